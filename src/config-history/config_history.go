@@ -0,0 +1,33 @@
+package confighistory
+
+import (
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+)
+
+// Revision is a structure applied at some point, Origin is either
+// a project file or the address of a configuration server client
+type Revision struct {
+	Number    int
+	AppliedAt time.Time
+	Origin    string
+	Summary   string
+	Structure serialization.OpcStructureModel
+}
+
+// ConfigHistory keeps the last applied structures, the oldest
+// revisions are dropped once the capacity is reached
+type ConfigHistory interface {
+	init(int)
+	Add(serialization.OpcStructureModel, string, string) Revision
+	List() []Revision
+	Get(int) (*Revision, error)
+	Current() int
+}
+
+func CreateNew(capacity int) ConfigHistory {
+	h := configHistoryImpl{}
+	h.init(capacity)
+	return &h
+}
@@ -0,0 +1,69 @@
+package confighistory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+)
+
+type configHistoryImpl struct {
+	lock      sync.RWMutex
+	capacity  int
+	revisions []Revision
+	next      int
+}
+
+func (h *configHistoryImpl) init(capacity int) {
+	h.capacity = max(capacity, 1)
+	h.revisions = make([]Revision, 0, h.capacity)
+	h.next = 1
+}
+
+func (h *configHistoryImpl) Add(m serialization.OpcStructureModel, origin string, summary string) Revision {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	r := Revision{
+		Number:    h.next,
+		AppliedAt: time.Now(),
+		Origin:    origin,
+		Summary:   summary,
+		Structure: m,
+	}
+	h.next += 1
+	if len(h.revisions) == h.capacity {
+		h.revisions = h.revisions[1:]
+	}
+	h.revisions = append(h.revisions, r)
+	return r
+}
+
+// List returns the revisions, the latest first
+func (h *configHistoryImpl) List() []Revision {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	res := make([]Revision, len(h.revisions))
+	for i, r := range h.revisions {
+		res[len(h.revisions)-1-i] = r
+	}
+	return res
+}
+
+func (h *configHistoryImpl) Get(n int) (*Revision, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for _, r := range h.revisions {
+		if r.Number == n {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("revision %d not found", n)
+}
+
+// Current returns the number of the last revision added, 0 if none was added yet
+func (h *configHistoryImpl) Current() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.next - 1
+}
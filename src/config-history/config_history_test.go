@@ -0,0 +1,56 @@
+package confighistory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+)
+
+func TestHistory(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		capacity int
+		added    int
+		listed   []int
+		evicted  []int
+	}{
+		{"empty", 3, 0, []int{}, []int{0, 1}},
+		{"below capacity", 3, 2, []int{2, 1}, []int{0, 3}},
+		{"at capacity", 3, 3, []int{3, 2, 1}, []int{0, 4}},
+		{"oldest evicted", 3, 5, []int{5, 4, 3}, []int{1, 2, 6}},
+		{"capacity raised to one", 0, 2, []int{2}, []int{1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := CreateNew(tc.capacity)
+			for i := 1; i <= tc.added; i++ {
+				r := h.Add(serialization.OpcStructureModel{}, "test", fmt.Sprintf("change %d", i))
+				if r.Number != i {
+					t.Fatalf("expected revision %d, got %d", i, r.Number)
+				}
+			}
+
+			if h.Current() != tc.added {
+				t.Errorf("expected current revision %d, got %d", tc.added, h.Current())
+			}
+			listed := h.List()
+			if len(listed) != len(tc.listed) {
+				t.Fatalf("expected revisions %v, got %d revisions", tc.listed, len(listed))
+			}
+			for i, n := range tc.listed {
+				if listed[i].Number != n {
+					t.Errorf("expected revision %d at %d, got %d", n, i, listed[i].Number)
+				}
+				r, err := h.Get(n)
+				if err != nil || r.Summary != fmt.Sprintf("change %d", n) {
+					t.Errorf("expected revision %d, got %+v, %v", n, r, err)
+				}
+			}
+			for _, n := range tc.evicted {
+				if r, err := h.Get(n); err == nil {
+					t.Errorf("expected revision %d not to be found, got %+v", n, r)
+				}
+			}
+		})
+	}
+}
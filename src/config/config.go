@@ -30,6 +30,9 @@ type Config struct {
 	OpcServerPort      uint16
 	TcpServerPort      uint16
 	EngineDebugEnabled bool
+	StateDir           string
+	RestoreState       bool
+	HistorySize        int
 }
 
 func GetConfig() Config {
@@ -46,6 +49,9 @@ func GetConfig() Config {
 		TcpServerPort:      getTcpPort(),
 		ServerAddress:      getIpAddress(),
 		EngineDebugEnabled: getEngineDebugEnabled(),
+		StateDir:           getStateDir(),
+		RestoreState:       getRestoreState(),
+		HistorySize:        getHistorySize(),
 	}
 }
 
@@ -181,3 +187,31 @@ func getEngineDebugEnabled() bool {
 	r, _ := strconv.ParseBool(d)
 	return r
 }
+
+func getStateDir() string {
+	d := getTrimmedEnvVar("OPC_ENGINE_SIMULATOR_STATE_DIR")
+	if d == "" {
+		l.Debug("state directory not set in environment, applied structures are not persisted")
+	} else {
+		l.Debug(fmt.Sprintf("using state directory %s from environment", d))
+	}
+	return d
+}
+
+func getRestoreState() bool {
+	r := getTrimmedEnvVar("OPC_ENGINE_SIMULATOR_RESTORE_STATE")
+	b, _ := strconv.ParseBool(r)
+	return b
+}
+
+func getHistorySize() int {
+	s := getTrimmedEnvVar("OPC_ENGINE_SIMULATOR_HISTORY_SIZE")
+	if n, err := strconv.ParseUint(s, 10, 16); err != nil || n == 0 {
+		defaultSize := 20
+		l.Debug(fmt.Sprintf("invalid history size %s, defaulting to %d", s, defaultSize))
+		return defaultSize
+	} else {
+		l.Debug(fmt.Sprintf("got history size %d from environment", n))
+		return int(n)
+	}
+}
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/AndreiLacatos/opc-engine/config"
+	confighistory "github.com/AndreiLacatos/opc-engine/config-history"
 	"github.com/AndreiLacatos/opc-engine/logging"
 	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
 	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
 	opcserver "github.com/AndreiLacatos/opc-engine/opc-server"
+	statestore "github.com/AndreiLacatos/opc-engine/state-store"
 	tcpserver "github.com/AndreiLacatos/opc-engine/tcp-server"
 	"go.uber.org/zap"
 )
@@ -23,6 +25,12 @@ var l *zap.Logger
 var configServer tcpserver.TcpServer
 var opcServer opcserver.OpcServer = nil
 var nodeEngine nodeengine.ValueChangeEngine = nil
+var currentStructure *opc.OpcStructure = nil
+var stateStore statestore.StateStore = nil
+var history confighistory.ConfigHistory
+
+// createOpcServer makes the OPC servers of the applied structures
+var createOpcServer = opcserver.CreateNew
 
 func main() {
 	c := config.GetConfig()
@@ -43,25 +51,37 @@ func main() {
 		}
 	}()
 
-	initialStructure := getInitialOpcStructure(c)
-	if initialStructure != nil {
-		setupOpc(c, initialStructure)
+	history = confighistory.CreateNew(c.HistorySize)
+	if c.StateDir != "" {
+		stateStore = statestore.CreateNew(c.StateDir, c.HistorySize, l)
+	}
+	restored := false
+	for _, stored := range getPersistedOpcStructures(c) {
+		l.Info(fmt.Sprintf("restoring structure applied on %v by %s", stored.AppliedAt, stored.Origin))
+		s := stored.Structure.ToDomain(l)
+		if err := applyStructure(c, &s); err != nil {
+			l.Error(fmt.Sprintf("error restoring persisted structure, falling back to the one before it, reason: %v", err))
+			continue
+		}
+		history.Add(stored.Structure, stored.Origin, "restored structure")
+		restored = true
+		break
+	}
+	if !restored {
+		if initialStructure := getInitialOpcStructure(c); initialStructure != nil {
+			s := initialStructure.ToDomain(l)
+			if err := commitStructure(c, *initialStructure, &s, c.ProjectPath, ""); err != nil {
+				l.Error(fmt.Sprintf("error setting up OPC server, reason: %v", err))
+			}
+		}
 	}
 
 	go func() {
 		commands := configServer.GetCommandChannel()
 		response := configServer.GetResponseChannel()
 		for {
-			s := <-commands
-			if err := teardownOpc(); err != nil {
-				l.Error(fmt.Sprintf("error tearing down OPC server, reason: %v", err))
-				response <- err
-			}
-			if err := setupOpc(c, &s); err != nil {
-				l.Error(fmt.Sprintf("error setting up OPC server, reason: %v", err))
-				response <- err
-			}
-			response <- nil
+			data, err := handleCommand(c, <-commands)
+			response <- tcpserver.CommandResult{Data: data, Err: err}
 		}
 	}()
 
@@ -84,18 +104,208 @@ func waitTerminationSignal() {
 	<-sigs
 }
 
-func setupOpc(c config.Config, s *opc.OpcStructure) error {
-	if s == nil {
-		l.Warn("missing OPC structure, aborting setup")
-		return nil
+func handleCommand(c config.Config, command any) (any, error) {
+	switch t := command.(type) {
+	case tcpserver.GetRevisionCommand:
+		return history.Current(), nil
+	case tcpserver.GetVersionCommand:
+		return c, nil
+	case tcpserver.GetStructureCommand:
+		return getCurrentModel()
+	case tcpserver.GetNodeCommand:
+		m, err := getCurrentModel()
+		if err != nil {
+			return nil, err
+		}
+		if t.NodeId != nil {
+			n, _, err := m.FindNode(t.NodeId.String())
+			return n, err
+		}
+		return m.FindNodeByPath(t.Path)
+	case tcpserver.ConfigureNodesCommand:
+		if err := checkRevision(t.ExpectedRevision); err != nil {
+			return nil, err
+		}
+		if err := commitStructure(c, t.Model, &t.Structure, t.Origin, ""); err != nil {
+			l.Error(fmt.Sprintf("error applying OPC structure, reason: %v", err))
+			return nil, err
+		}
+		return nil, nil
+	case tcpserver.AddNodeCommand:
+		return editStructure(c, t.Origin, t.ExpectedRevision, func(m *serialization.OpcStructureModel) (*serialization.OpcStructureNodeModel, error) {
+			if err := m.AddNode(t.ParentId.String(), t.Node); err != nil {
+				return nil, err
+			}
+			n, _, err := m.FindNode(t.Node.Id)
+			return n, err
+		})
+	case tcpserver.RemoveNodeCommand:
+		return editStructure(c, t.Origin, t.ExpectedRevision, func(m *serialization.OpcStructureModel) (*serialization.OpcStructureNodeModel, error) {
+			return m.RemoveNode(t.NodeId.String())
+		})
+	case tcpserver.UpdateNodeCommand:
+		return editStructure(c, t.Origin, t.ExpectedRevision, func(m *serialization.OpcStructureModel) (*serialization.OpcStructureNodeModel, error) {
+			n, _, err := m.FindNode(t.NodeId.String())
+			if err != nil {
+				return nil, err
+			}
+			if t.Label != nil {
+				if *t.Label == "" {
+					return nil, fmt.Errorf("the label of %s can not be empty", n.Label)
+				}
+				n.Label = *t.Label
+			}
+			if t.Waveform != nil {
+				if n.NodeType != "value" {
+					return nil, fmt.Errorf("%s is a %s node, only value nodes have a waveform", n.Label, n.NodeType)
+				}
+				n.Waveform = t.Waveform
+			}
+			return n, nil
+		})
+	case tcpserver.MoveNodeCommand:
+		return editStructure(c, t.Origin, t.ExpectedRevision, func(m *serialization.OpcStructureModel) (*serialization.OpcStructureNodeModel, error) {
+			if _, err := m.MoveNode(t.NodeId.String(), t.ParentId.String()); err != nil {
+				return nil, err
+			}
+			n, _, err := m.FindNode(t.NodeId.String())
+			return n, err
+		})
+	case tcpserver.ListRevisionsCommand:
+		return history.List(), nil
+	case tcpserver.DiffRevisionsCommand:
+		from, err := history.Get(t.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := history.Get(t.To)
+		if err != nil {
+			return nil, err
+		}
+		return opc.Diff(from.Structure.ToDomain(l), to.Structure.ToDomain(l)), nil
+	case tcpserver.RollbackCommand:
+		if err := checkRevision(t.ExpectedRevision); err != nil {
+			return nil, err
+		}
+		r, err := history.Get(t.Revision)
+		if err != nil {
+			return nil, err
+		}
+		s := r.Structure.ToDomain(l)
+		summary := fmt.Sprintf("rollback to revision %d: %s", r.Number, describeChanges(&s))
+		if err := commitStructure(c, r.Structure, &s, t.Origin, summary); err != nil {
+			l.Error(fmt.Sprintf("error rolling back to revision %d, reason: %v", r.Number, err))
+			return nil, err
+		}
+		return nil, nil
 	}
 
-	if opcServer != nil || nodeEngine != nil {
-		l.Warn("OPC server or node engine not properly disposed, abortint setup")
+	if nodeEngine == nil {
+		return nil, fmt.Errorf("node engine not running")
+	}
+	switch t := command.(type) {
+	case tcpserver.InjectFaultCommand:
+		return nil, nodeEngine.InjectFault(t.NodeId, t.Fault)
+	case tcpserver.ClearFaultsCommand:
+		return nil, nodeEngine.ClearFaults(t.NodeId)
+	case tcpserver.GetOverridesCommand:
+		return nodeEngine.GetOverrides(), nil
+	case tcpserver.ClearOverrideCommand:
+		return nil, nodeEngine.ClearOverride(t.NodeId)
+	case tcpserver.GetScenariosCommand:
+		return nodeEngine.GetScenarios(), nil
+	case tcpserver.StartScenarioCommand:
+		return nil, nodeEngine.StartScenario(t.Name)
+	case tcpserver.StopScenarioCommand:
+		return nil, nodeEngine.StopScenario()
+	case tcpserver.GetValuesCommand:
+		return nodeEngine.GetValues(t.NodeIds)
+	case tcpserver.GetStatusCommand:
+		return nodeEngine.GetStatus(), nil
+	default:
+		l.Warn(fmt.Sprintf("unsupported command %T", command))
+		return nil, fmt.Errorf("unsupported command")
+	}
+}
+
+// checkRevision rejects changes based on a revision other than the current one,
+// changes without an expected revision are always accepted
+func checkRevision(expected *int) error {
+	if expected == nil {
 		return nil
 	}
+	if current := history.Current(); *expected != current {
+		l.Warn(fmt.Sprintf("rejecting change based on revision %d, current revision is %d", *expected, current))
+		return tcpserver.ConflictError{Expected: *expected, Current: current}
+	}
+	return nil
+}
+
+// applyStructure replaces the running structure; the new structure is
+// validated & its OPC server built before the running one is torn down,
+// if the running server fails to stop or the new one fails to start the
+// previous structure is restored
+func applyStructure(c config.Config, s *opc.OpcStructure) error {
+	if err := nodeengine.Validate(*s); err != nil {
+		return fmt.Errorf("invalid structure: %w", err)
+	}
+
+	previous := currentStructure
+	reconfigureFailed := false
+	if currentStructure != nil {
+		err := reconfigureOpc(s)
+		if err == nil {
+			return nil
+		}
+		l.Warn(fmt.Sprintf("could not reconfigure OPC server in place, rebuilding it, reason: %v", err))
+		reconfigureFailed = true
+	}
+
+	srv, err := buildOpc(c, s)
+	if err != nil {
+		if !reconfigureFailed {
+			return err
+		}
+		// the running address space might have been changed partially
+		if teardownErr := teardownOpc(); teardownErr != nil {
+			l.Warn(fmt.Sprintf("could not tear down partially reconfigured OPC server, reason: %v", teardownErr))
+		}
+		return restorePrevious(c, previous, err)
+	}
+	if err := teardownOpc(); err != nil {
+		l.Warn(fmt.Sprintf("could not tear down running OPC server, restoring previous structure, reason: %v", err))
+		return restorePrevious(c, previous, err)
+	}
+	if err := startOpc(c, srv, s); err != nil {
+		if previous == nil {
+			return err
+		}
+		l.Warn(fmt.Sprintf("could not start new OPC server, restoring previous structure, reason: %v", err))
+		return restorePrevious(c, previous, err)
+	}
+	return nil
+}
+
+// restorePrevious brings the previous structure back after the
+// new one failed to be applied, err is the reason of the failure
+func restorePrevious(c config.Config, previous *opc.OpcStructure, err error) error {
+	if restoreErr := restoreOpc(c, previous); restoreErr != nil {
+		return fmt.Errorf("%v, restoring the previous structure failed as well: %v", err, restoreErr)
+	}
+	return fmt.Errorf("%v, previous structure restored", err)
+}
+
+func restoreOpc(c config.Config, s *opc.OpcStructure) error {
+	srv, err := buildOpc(c, s)
+	if err != nil {
+		return err
+	}
+	return startOpc(c, srv, s)
+}
 
-	opcServer = opcserver.CreateNew(opcserver.OpcServerConfig{
+// buildOpc sets up an OPC server with the node structure, without starting it
+func buildOpc(c config.Config, s *opc.OpcStructure) (opcserver.OpcServer, error) {
+	srv := createOpcServer(opcserver.OpcServerConfig{
 		ServerName:        "test-server",
 		ServerEndpointUrl: c.ServerAddress,
 		Port:              c.OpcServerPort,
@@ -105,18 +315,26 @@ func setupOpc(c config.Config, s *opc.OpcStructure) error {
 		},
 	}, l)
 
-	if err := opcServer.Setup(); err != nil {
+	if err := srv.Setup(); err != nil {
 		l.Error(fmt.Sprintf("could not set up OPC server: %v", err))
-		return err
+		return nil, err
 	}
-	if err := opcServer.SetNodeStructure(*s); err != nil {
+	if err := srv.SetNodeStructure(*s); err != nil {
 		l.Error(fmt.Sprintf("some nodes might not have been added correctly: %v", err))
-		return err
+		return nil, err
+	}
+	return srv, nil
+}
+
+// startOpc starts a server built by buildOpc along with the node engine
+func startOpc(c config.Config, srv opcserver.OpcServer, s *opc.OpcStructure) error {
+	if opcServer != nil || nodeEngine != nil {
+		return fmt.Errorf("OPC server or node engine not properly disposed")
 	}
 
 	var opcStartErr error = nil
 	go func() {
-		if err := opcServer.Start(); err != nil {
+		if err := srv.Start(); err != nil {
 			opcStartErr = err
 		}
 	}()
@@ -128,10 +346,30 @@ func setupOpc(c config.Config, s *opc.OpcStructure) error {
 		l.Info("started OPC server")
 	}
 
+	opcServer = srv
 	nodeEngine = nodeengine.CreateNew(*s, l, c.EngineDebugEnabled)
 	go opcServer.Subscribe(nodeEngine.EventChannel())
+	go nodeEngine.SubscribeWrites(opcServer.WriteChannel())
 	go nodeEngine.Start()
+	currentStructure = s
+
+	return nil
+}
 
+// reconfigureOpc applies only the changes of the new structure to the running
+// OPC server & node engine, client sessions are kept
+func reconfigureOpc(s *opc.OpcStructure) error {
+	if opcServer == nil || nodeEngine == nil {
+		return fmt.Errorf("OPC server or node engine not running")
+	}
+
+	d := opc.Diff(*currentStructure, *s)
+	if err := opcServer.UpdateNodeStructure(d); err != nil {
+		return err
+	}
+	nodeEngine.Reconfigure(*s, d)
+	currentStructure = s
+	l.Info("reconfigured OPC server in place")
 	return nil
 }
 
@@ -142,17 +380,118 @@ func teardownOpc() error {
 	}
 
 	nodeEngine.Stop()
-	if err := opcServer.Stop(); err != nil {
+	err := opcServer.Stop()
+
+	// the engine is stopped either way, a server
+	// that failed to stop is not used any more
+	opcServer = nil
+	nodeEngine = nil
+	currentStructure = nil
+	if err != nil {
 		l.Warn(fmt.Sprintf("could not stop OPC server, reason: %v", err))
 		return err
 	}
+	return nil
+}
 
-	opcServer = nil
-	nodeEngine = nil
+// commitStructure applies the structure & records it as a new
+// revision, the summary describes the changes if not given
+func commitStructure(c config.Config, m serialization.OpcStructureModel, s *opc.OpcStructure, origin string, summary string) error {
+	if summary == "" {
+		summary = describeChanges(s)
+	}
+	if err := applyStructure(c, s); err != nil {
+		return err
+	}
+	r := history.Add(m, origin, summary)
+	l.Info(fmt.Sprintf("applied revision %d from %s: %s", r.Number, origin, summary))
+	persistStructure(m, origin)
 	return nil
 }
 
-func getInitialOpcStructure(c config.Config) *opc.OpcStructure {
+// editStructure applies an edit to a copy of the running structure & commits
+// the result as a new revision, the edited node is returned
+func editStructure(
+	c config.Config,
+	origin string,
+	expectedRevision *int,
+	edit func(*serialization.OpcStructureModel) (*serialization.OpcStructureNodeModel, error),
+) (*serialization.OpcStructureNodeModel, error) {
+	if err := checkRevision(expectedRevision); err != nil {
+		return nil, err
+	}
+	current, err := getCurrentModel()
+	if err != nil {
+		return nil, err
+	}
+	m, err := current.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := edit(m)
+	if err != nil {
+		return nil, err
+	}
+	s := m.ToDomain(l)
+	if err := commitStructure(c, *m, &s, origin, ""); err != nil {
+		l.Error(fmt.Sprintf("error applying OPC structure, reason: %v", err))
+		return nil, err
+	}
+	return n, nil
+}
+
+// getCurrentModel returns the running structure as it was applied,
+// it must not be modified
+func getCurrentModel() (*serialization.OpcStructureModel, error) {
+	r, err := history.Get(history.Current())
+	if err != nil || currentStructure == nil {
+		return nil, fmt.Errorf("no structure applied yet")
+	}
+	return &r.Structure, nil
+}
+
+func describeChanges(s *opc.OpcStructure) string {
+	if currentStructure == nil {
+		return "initial structure"
+	}
+	d := opc.Diff(*currentStructure, *s)
+	return d.Summary()
+}
+
+// persistStructure stores the applied structure, so that it can be
+// restored on startup; failing to store it does not fail the command
+func persistStructure(m serialization.OpcStructureModel, origin string) {
+	if stateStore == nil {
+		return
+	}
+	if err := stateStore.Save(statestore.StoredStructure{
+		AppliedAt: time.Now(),
+		Origin:    origin,
+		Structure: m,
+	}); err != nil {
+		l.Warn(fmt.Sprintf("could not persist applied structure, reason: %v", err))
+	}
+}
+
+// getPersistedOpcStructures returns the stored structures to restore, the latest first
+func getPersistedOpcStructures(c config.Config) []statestore.StoredStructure {
+	if !c.RestoreState || stateStore == nil {
+		return nil
+	}
+
+	stored, err := stateStore.LoadAll()
+	if err != nil {
+		l.Error(fmt.Sprintf("error loading persisted structures: %v", err))
+		return nil
+	}
+	if len(stored) == 0 {
+		l.Info("no persisted structure found")
+	}
+	return stored
+}
+
+func getInitialOpcStructure(c config.Config) *serialization.OpcStructureModel {
 	if c.ProjectPath == "" {
 		return nil
 	}
@@ -170,6 +509,5 @@ func getInitialOpcStructure(c config.Config) *opc.OpcStructure {
 		l.Error(fmt.Sprintf("error decoding JSON: %v", err))
 		return nil
 	}
-	structure := structureModel.ToDomain(l)
-	return &structure
+	return &structureModel
 }
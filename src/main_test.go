@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/config"
+	confighistory "github.com/AndreiLacatos/opc-engine/config-history"
+	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+	opcserver "github.com/AndreiLacatos/opc-engine/opc-server"
+	tcpserver "github.com/AndreiLacatos/opc-engine/tcp-server"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCheckRevision(t *testing.T) {
+	l = zap.NewNop()
+	revision := func(n int) *int { return &n }
+	for _, tc := range []struct {
+		name     string
+		applied  int
+		expected *int
+		conflict bool
+	}{
+		{"no expected revision", 2, nil, false},
+		{"current revision", 2, revision(2), false},
+		{"nothing applied yet", 0, revision(0), false},
+		{"older revision", 2, revision(1), true},
+		{"newer revision", 2, revision(3), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			history = confighistory.CreateNew(10)
+			for range tc.applied {
+				history.Add(serialization.OpcStructureModel{}, "test", "")
+			}
+
+			err := checkRevision(tc.expected)
+
+			if !tc.conflict {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			var conflict tcpserver.ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("expected a conflict, got %v", err)
+			}
+			if conflict.Expected != *tc.expected || conflict.Current != tc.applied {
+				t.Errorf("expected a conflict between %d & %d, got %+v", *tc.expected, tc.applied, conflict)
+			}
+		})
+	}
+}
+
+// fakeOpcServer is an OpcServer failing with the given errors
+type fakeOpcServer struct {
+	setErr    error
+	updateErr error
+	stopErr   error
+}
+
+func (s *fakeOpcServer) Setup() error                                { return nil }
+func (s *fakeOpcServer) SetNodeStructure(opc.OpcStructure) error     { return s.setErr }
+func (s *fakeOpcServer) UpdateNodeStructure(opc.StructureDiff) error { return s.updateErr }
+func (s *fakeOpcServer) Start() error                                { return nil }
+func (s *fakeOpcServer) Stop() error                                 { return s.stopErr }
+func (s *fakeOpcServer) WriteChannel() chan nodeengine.NodeValueWrite {
+	return make(chan nodeengine.NodeValueWrite)
+}
+func (s *fakeOpcServer) Subscribe(c chan nodeengine.NodeValueChange) {
+	for range c {
+	}
+}
+
+func TestApplyStructure_RestoresPreviousStructureWhenTeardownFails(t *testing.T) {
+	l = zap.NewNop()
+	rootId := uuid.MustParse("3d4e5f6a-7b8c-4d9e-8f1a-2b3c4d5e6f7a")
+	for _, tc := range []struct {
+		name  string
+		built *fakeOpcServer
+		err   string
+	}{
+		{"after building the new server", &fakeOpcServer{}, "stop failed, previous structure restored"},
+		{"after failing to build the new server", &fakeOpcServer{setErr: errors.New("build failed")}, "build failed, previous structure restored"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			restored := &fakeOpcServer{}
+			servers := []opcserver.OpcServer{tc.built, restored}
+			createOpcServer = func(opcserver.OpcServerConfig, *zap.Logger) opcserver.OpcServer {
+				srv := servers[0]
+				servers = servers[1:]
+				return srv
+			}
+			t.Cleanup(func() {
+				// the restored engine is started in the background, it is left running
+				opcServer, nodeEngine, currentStructure = nil, nil, nil
+				createOpcServer = opcserver.CreateNew
+			})
+			previous := &opc.OpcStructure{Root: opcnode.OpcContainerNode{Id: rootId, Label: "Root"}}
+			opcServer = &fakeOpcServer{updateErr: errors.New("update failed"), stopErr: errors.New("stop failed")}
+			nodeEngine = nodeengine.CreateNew(*previous, l, false)
+			nodeEngine.Start()
+			currentStructure = previous
+
+			err := applyStructure(config.Config{}, &opc.OpcStructure{Root: opcnode.OpcContainerNode{Id: rootId, Label: "Plant"}})
+
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected an error containing %q, got %v", tc.err, err)
+			}
+			if currentStructure != previous || opcServer != restored || nodeEngine == nil {
+				t.Errorf("expected the previous structure to be served by a new server & engine")
+			}
+		})
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"time"
 
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
 )
 
 type DelayCalculator interface {
-	init(waveform.Waveform)
+	init(waveform.Waveform, simulationclock.SimulationClock)
+	GetStartingTickIndex() int64
+	GetCurrentTickTime() time.Time
 	GetDelayUntilNextTick() time.Duration
 }
 
-func CreateNew(w waveform.Waveform) DelayCalculator {
+// CreateNew makes a delay calculator that schedules ticks on the simulated
+// time of the given clock, without a clock ticks follow the wall clock
+func CreateNew(w waveform.Waveform, s simulationclock.SimulationClock) DelayCalculator {
 	c := delayCalculatorImpl{}
-	c.init(w)
+	c.init(w, s)
 	return &c
 }
@@ -4,17 +4,47 @@ import (
 	"time"
 
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
 )
 
 type delayCalculatorImpl struct {
-	waveform      waveform.Waveform
-	nextTickIndex int
-	tickSchedule  []time.Time
+	waveform          waveform.Waveform
+	nextTickIndex     int
+	tickSchedule      []time.Time
+	startingTickIndex int64
+	alignedCycleStart time.Time
+	currentTickTime   time.Time
+	clock             simulationclock.SimulationClock
 }
 
-func (c *delayCalculatorImpl) init(w waveform.Waveform) {
+func (c *delayCalculatorImpl) init(w waveform.Waveform, s simulationclock.SimulationClock) {
 	c.waveform = w
+	c.clock = s
+	if c.clock == nil {
+		c.clock = simulationclock.CreateNew()
+	}
 	c.nextTickIndex = 0
+	c.startingTickIndex = 0
+	c.currentTickTime = c.clock.Now()
+
+	if w.Alignment != nil {
+		// determine where in the cycle the wall clock currently is, the
+		// engine starts emitting from the tick that contains the current phase
+		var phase time.Duration
+		c.alignedCycleStart, phase = c.getAlignedCycleStart(c.clock.Now())
+		c.startingTickIndex = phase.Milliseconds() / int64(w.TickFrequency)
+		c.currentTickTime = c.addWallClock(c.alignedCycleStart, time.Duration(c.startingTickIndex*int64(w.TickFrequency))*time.Millisecond)
+	}
+}
+
+func (c *delayCalculatorImpl) GetStartingTickIndex() int64 {
+	return c.startingTickIndex
+}
+
+// GetCurrentTickTime returns the scheduled time of the tick the engine is at,
+// i.e. the one that was last waited for
+func (c *delayCalculatorImpl) GetCurrentTickTime() time.Time {
+	return c.currentTickTime
 }
 
 func (c *delayCalculatorImpl) GetDelayUntilNextTick() time.Duration {
@@ -23,7 +53,8 @@ func (c *delayCalculatorImpl) GetDelayUntilNextTick() time.Duration {
 		c.nextTickIndex = 0
 	}
 
-	delay := time.Until(c.tickSchedule[c.nextTickIndex])
+	c.currentTickTime = c.tickSchedule[c.nextTickIndex]
+	delay := c.clock.Until(c.currentTickTime)
 	c.nextTickIndex += 1
 	return delay
 }
@@ -37,25 +68,78 @@ func (c *delayCalculatorImpl) makeCycleSchedule() {
 
 	cyclesToPreschedule := 25
 	schedule := make([]time.Time, 0, scheduleLength*int64(cyclesToPreschedule))
-	startTime := time.Now().Add(time.Duration(cyclesToPreschedule*int(scheduleLength)*-2) * time.Microsecond)
+	startTime := c.clock.Now().Add(time.Duration(cyclesToPreschedule*int(scheduleLength)*-2) * time.Microsecond)
+
+	// ticks already elapsed in the first cycle are skipped, this only
+	// happens the first time an aligned waveform is scheduled
+	skipUntil := int64(0)
+	if c.waveform.Alignment != nil {
+		if c.tickSchedule == nil {
+			startTime = c.alignedCycleStart
+			skipUntil = c.startingTickIndex
+		} else {
+			startTime, _ = c.getAlignedCycleStart(c.clock.Now())
+		}
+	}
+
+	// aligned cycles are laid out on the wall clock of their anchor
+	at := func(d time.Duration) time.Time {
+		if c.waveform.Alignment != nil {
+			return c.addWallClock(startTime, d)
+		}
+		return startTime.Add(d)
+	}
 
 	// schedule ticks for the next N cycles
+	cycle := time.Duration(c.waveform.Duration) * time.Millisecond
 	for j := 0; j < cyclesToPreschedule; j++ {
+		cycleStart := time.Duration(j) * cycle
 		// schedule tick for an entire cycle
 		for i := int64(1); i <= tickCount; i++ {
+			if j == 0 && i <= skipUntil {
+				continue
+			}
 			tickDelay := time.Duration(i*int64(c.waveform.TickFrequency)) * time.Millisecond
-			schedule = append(schedule, startTime.Add(tickDelay))
+			schedule = append(schedule, at(cycleStart+tickDelay))
 		}
 		if c.waveform.Duration%int64(c.waveform.TickFrequency) != 0 {
 			// add an extra scheduled date when the last tick does not intersect
 			// with the duration of the cycle
-			cycleEnd := time.Duration(c.waveform.Duration) * time.Millisecond
-			schedule = append(schedule, startTime.Add(cycleEnd))
+			schedule = append(schedule, at(cycleStart+cycle))
 		}
-
-		// compute the start time of the next cycle
-		startTime = startTime.Add(time.Duration(c.waveform.Duration) * time.Millisecond)
 	}
 
 	c.tickSchedule = schedule
 }
+
+// getAlignedCycleStart returns the start of the cycle the wall clock is in
+// & how far into the cycle it is
+func (c *delayCalculatorImpl) getAlignedCycleStart(now time.Time) (time.Time, time.Duration) {
+	// the phase & the cycle start are computed on wall-clock time in the anchor's
+	// location, this way anchors such as midnight stay in place across DST changes
+	anchor := c.waveform.Alignment.Anchor
+	loc := anchor.Location()
+	wallNow := toWallClock(now, loc)
+	cycle := time.Duration(c.waveform.Duration) * time.Millisecond
+	phase := wallNow.Sub(toWallClock(anchor, loc)) % cycle
+	if phase < 0 {
+		// anchor lies in the future
+		phase += cycle
+	}
+	return fromWallClock(wallNow.Add(-phase), loc), phase
+}
+
+// addWallClock advances t by d on the wall clock of the anchor's location
+func (c *delayCalculatorImpl) addWallClock(t time.Time, d time.Duration) time.Time {
+	loc := c.waveform.Alignment.Anchor.Location()
+	return fromWallClock(toWallClock(t, loc).Add(d), loc)
+}
+
+func toWallClock(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+}
+
+func fromWallClock(w time.Time, loc *time.Location) time.Time {
+	return time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), w.Nanosecond(), loc)
+}
@@ -0,0 +1,88 @@
+package delaycalculator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+)
+
+func TestGetAlignedCycleStart(t *testing.T) {
+	bucharest, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, time.April, 1, 10, 0, 0, 0, bucharest)
+	for _, tc := range []struct {
+		name     string
+		anchor   time.Time
+		duration int64
+		want     time.Time
+	}{
+		{"anchor in the past", now.Add(-1050 * time.Millisecond), 2000, now.Add(-1050 * time.Millisecond)},
+		{"anchor cycles ago", now.Add(-5050 * time.Millisecond), 2000, now.Add(-1050 * time.Millisecond)},
+		{"anchor in the future", now.Add(1500 * time.Millisecond), 2000, now.Add(-500 * time.Millisecond)},
+		{"anchor at now", now, 2000, now},
+		{"anchor in another location", time.Date(2024, time.April, 1, 6, 30, 0, 0, time.UTC), 3600000, time.Date(2024, time.April, 1, 9, 30, 0, 0, bucharest)},
+		{
+			"daily cycle across a daylight saving change",
+			time.Date(2024, time.March, 30, 0, 0, 0, 0, bucharest),
+			24 * 3600000,
+			time.Date(2024, time.April, 1, 0, 0, 0, 0, bucharest),
+		},
+		{
+			"cycle spanning a daylight saving change",
+			time.Date(2024, time.March, 30, 0, 0, 0, 0, bucharest),
+			72 * 3600000,
+			time.Date(2024, time.March, 30, 0, 0, 0, 0, bucharest),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := delayCalculatorImpl{
+				waveform: waveform.Waveform{
+					Duration:      tc.duration,
+					TickFrequency: 100,
+					Alignment:     &waveform.WaveformAlignment{Anchor: tc.anchor},
+				},
+			}
+
+			if s, _ := c.getAlignedCycleStart(now); !s.Equal(tc.want) {
+				t.Errorf("expected the cycle to start at %v, got %v", tc.want, s)
+			}
+		})
+	}
+}
+
+func TestMakeCycleSchedule_AlignedCyclesKeepTheirWallClockTimeAcrossDaylightSavingChanges(t *testing.T) {
+	bucharest, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clocks are moved forward on the 31st of March & back on the 27th of October
+	for _, start := range []time.Time{
+		time.Date(2024, time.March, 30, 0, 0, 0, 0, bucharest),
+		time.Date(2024, time.October, 26, 0, 0, 0, 0, bucharest),
+	} {
+		t.Run(start.Format(time.DateOnly), func(t *testing.T) {
+			c := delayCalculatorImpl{
+				waveform: waveform.Waveform{
+					Duration:      24 * 3600000,
+					TickFrequency: 6 * 3600000,
+					Alignment:     &waveform.WaveformAlignment{Anchor: start},
+				},
+				clock:             simulationclock.CreateNew(),
+				alignedCycleStart: start,
+			}
+
+			c.makeCycleSchedule()
+
+			for i, tick := range c.tickSchedule[:8] {
+				want := time.Date(start.Year(), start.Month(), start.Day()+(i+1)/4, (i+1)%4*6, 0, 0, 0, bucharest)
+				if !tick.Equal(want) {
+					t.Errorf("expected tick %d at %v, got %v", i, want, tick.In(bucharest))
+				}
+			}
+		})
+	}
+}
@@ -1,30 +1,93 @@
 package nodeengine
 
 import (
+	"time"
+
+	faultinjector "github.com/AndreiLacatos/opc-engine/node-engine/fault_injector"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
 	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	overridetracker "github.com/AndreiLacatos/opc-engine/node-engine/override_tracker"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type NodeValueChange struct {
-	Node     opcnode.OpcValueNode
-	NewValue waveformvalue.WaveformPointValue
+	Node      opcnode.OpcValueNode
+	NewValue  waveformvalue.WaveformPointValue
+	Status    quality.StatusCode
+	Timestamp time.Time
+}
+
+// NodeValueWrite is a value written by an OPC client
+type NodeValueWrite struct {
+	NodeId uuid.UUID
+	Value  waveformvalue.WaveformPointValue
+}
+
+type NodeOverride struct {
+	Node  opcnode.OpcValueNode
+	State override.OverrideState
 }
 
 type ValueChangeEngine interface {
 	Start()
 	EventChannel() chan NodeValueChange
+	InjectFault(uuid.UUID, fault.Fault) error
+	ClearFaults(uuid.UUID) error
+	SubscribeWrites(chan NodeValueWrite)
+	GetOverrides() []NodeOverride
+	ClearOverride(uuid.UUID) error
+	SwitchWaveform(uuid.UUID, waveform.Waveform) error
+	SetSpeed(float64) error
+	EnableNodes(uuid.UUID) error
+	DisableNodes(uuid.UUID) error
+	Reconfigure(opc.OpcStructure, opc.StructureDiff)
+	StartScenario(string) error
+	StopScenario() error
+	GetScenarios() []ScenarioStatus
+	GetValues([]uuid.UUID) ([]NodeValue, error)
+	GetStatus() EngineStatus
 	Stop()
 }
 
 func CreateNew(s opc.OpcStructure, l *zap.Logger, debug bool) ValueChangeEngine {
-	return &valueChangeEngineImpl{
-		Nodes:        extractValueNodes(s.Root),
+	logger := l.Named("ENGINE")
+	nodes := extractValueNodes(s.Root)
+	clock := simulationclock.CreateNew()
+	faults := make(map[uuid.UUID]faultinjector.FaultInjector)
+	overrides := make(map[uuid.UUID]overridetracker.OverrideTracker)
+	for _, n := range nodes {
+		faults[n.Id] = faultinjector.CreateNew(n.Faults, clock, logger.Named(n.Label))
+		if n.Override != nil {
+			overrides[n.Id] = overridetracker.CreateNew(*n.Override)
+		}
+	}
+	e := &valueChangeEngineImpl{
+		Root:         s.Root,
+		Nodes:        nodes,
+		Templates:    extractTemplateNodes(s.Root),
 		Events:       make(chan NodeValueChange),
-		Logger:       l.Named("ENGINE"),
+		Logger:       logger,
 		DebugEnabled: debug,
+		Faults:       faults,
+		Overrides:    overrides,
+		Done:         make(chan struct{}),
+		Values:       newValueStore(),
+		Clock:        clock,
+		Loops:        make(map[uuid.UUID]nodeLoop),
+		Disabled:     newNodeSet(),
+		Triggers:     newTriggerEvaluator(s.Triggers),
+		Actions:      make(chan trigger.Action, 64),
 	}
+	e.Scenarios = newScenarioRunner(s.Scenarios, e)
+	return e
 }
 
 func extractValueNodes(r opcnode.OpcContainerNode) []opcnode.OpcValueNode {
@@ -34,9 +97,59 @@ func extractValueNodes(r opcnode.OpcContainerNode) []opcnode.OpcValueNode {
 		switch t := n.(type) {
 		case *opcnode.OpcContainerNode:
 			res = append(res, extractValueNodes(*t)...)
+		case *opcnode.OpcTemplateNode:
+			res = append(res, extractValueNodes(opcnode.OpcContainerNode{Children: t.Children})...)
 		case *opcnode.OpcValueNode:
 			res = append(res, *t)
 		}
 	}
 	return res
 }
+
+func extractTemplateNodes(r opcnode.OpcContainerNode) []opcnode.OpcTemplateNode {
+	res := make([]opcnode.OpcTemplateNode, 0)
+
+	for _, n := range r.Children {
+		switch t := n.(type) {
+		case *opcnode.OpcContainerNode:
+			res = append(res, extractTemplateNodes(*t)...)
+		case *opcnode.OpcTemplateNode:
+			res = append(res, *t)
+		}
+	}
+	return res
+}
+
+// findSubtreeValueIds returns the ids of the value nodes in the subtree
+// of the node with the given id, or nil if there is no such node
+func findSubtreeValueIds(r opcnode.OpcContainerNode, id uuid.UUID) []uuid.UUID {
+	if r.Id == id {
+		return collectValueIds(r)
+	}
+	for _, n := range r.Children {
+		switch t := n.(type) {
+		case *opcnode.OpcContainerNode:
+			if ids := findSubtreeValueIds(*t, id); ids != nil {
+				return ids
+			}
+		case *opcnode.OpcTemplateNode:
+			if ids := findSubtreeValueIds(opcnode.OpcContainerNode{Id: t.Id, Children: t.Children}, id); ids != nil {
+				return ids
+			}
+		case *opcnode.OpcValueNode:
+			if t.Id == id {
+				return []uuid.UUID{t.Id}
+			}
+		}
+	}
+	return nil
+}
+
+func collectValueIds(r opcnode.OpcContainerNode) []uuid.UUID {
+	nodes := extractValueNodes(r)
+	res := make([]uuid.UUID, len(nodes))
+	for i, n := range nodes {
+		res[i] = n.Id
+	}
+	return res
+}
@@ -3,24 +3,68 @@ package nodeengine
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"os"
 	"sync"
 	"time"
 
 	delaycalculator "github.com/AndreiLacatos/opc-engine/node-engine/delay_calculator"
+	faultinjector "github.com/AndreiLacatos/opc-engine/node-engine/fault_injector"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
 	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	overridetracker "github.com/AndreiLacatos/opc-engine/node-engine/override_tracker"
+	qualitycalculator "github.com/AndreiLacatos/opc-engine/node-engine/quality_calculator"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	templatesimulators "github.com/AndreiLacatos/opc-engine/node-engine/template_simulators"
 	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type valueChangeEngineImpl struct {
+	Root         opcnode.OpcContainerNode
 	Nodes        []opcnode.OpcValueNode
+	Templates    []opcnode.OpcTemplateNode
+	Context      context.Context
 	Cancel       context.CancelFunc
 	Events       chan NodeValueChange
 	Logger       *zap.Logger
 	DebugEnabled bool
 	Teardown     *sync.WaitGroup
+	Faults       map[uuid.UUID]faultinjector.FaultInjector
+	Overrides    map[uuid.UUID]overridetracker.OverrideTracker
+	Done         chan struct{}
+	Values       *valueStore
+	Clock        simulationclock.SimulationClock
+	Loops        map[uuid.UUID]nodeLoop
+	Disabled     *nodeSet
+	Scenarios    *scenarioRunner
+	Triggers     *triggerEvaluator
+	Actions      chan trigger.Action
+	Started      time.Time
+	lock         sync.Mutex
+	registryLock sync.RWMutex
+}
+
+// nodeLoop is the handle of a running engine loop,
+// Done is closed once the loop has quit
+type nodeLoop struct {
+	Cancel context.CancelFunc
+	Done   chan struct{}
+}
+
+func (l *nodeLoop) isActive() bool {
+	select {
+	case <-l.Done:
+		return false
+	default:
+		return true
+	}
 }
 
 func (e *valueChangeEngineImpl) Start() {
@@ -28,18 +72,60 @@ func (e *valueChangeEngineImpl) Start() {
 	ctx, cancel := context.WithCancel(context.Background())
 	e.Teardown = &sync.WaitGroup{}
 	e.Cancel = cancel
+	e.lock.Lock()
+	e.Context = ctx
+	e.Started = time.Now()
 	for _, n := range e.Nodes {
-		go e.executeEngineLoop(ctx, n)
+		if n.Waveform.WaveformType == waveform.Simulated {
+			// published by the loop of their template
+			continue
+		}
+		e.startEngineLoop(ctx, n)
+	}
+	for _, t := range e.Templates {
+		e.startTemplateLoop(ctx, t)
+	}
+	e.lock.Unlock()
+	go e.executeTriggerLoop(ctx)
+}
+
+// startEngineLoop runs the loop of a value node so that
+// it can be stopped on its own, the caller holds the lock
+func (e *valueChangeEngineImpl) startEngineLoop(ctx context.Context, n opcnode.OpcValueNode) {
+	e.startLoop(ctx, n.Id, func(c context.Context) {
+		e.executeEngineLoop(c, n)
+	})
+}
+
+// startTemplateLoop runs the loop of a template node so that
+// it can be stopped on its own, the caller holds the lock
+func (e *valueChangeEngineImpl) startTemplateLoop(ctx context.Context, n opcnode.OpcTemplateNode) {
+	e.startLoop(ctx, n.Id, func(c context.Context) {
+		e.executeTemplateLoop(c, n)
+	})
+}
+
+func (e *valueChangeEngineImpl) startLoop(ctx context.Context, id uuid.UUID, run func(context.Context)) {
+	loopCtx, cancel := context.WithCancel(ctx)
+	l := nodeLoop{
+		Cancel: cancel,
+		Done:   make(chan struct{}),
 	}
+	e.Loops[id] = l
+	go func() {
+		defer close(l.Done)
+		run(loopCtx)
+	}()
 }
 
 func (e *valueChangeEngineImpl) executeEngineLoop(ctx context.Context, n opcnode.OpcValueNode) {
 	e.Logger.Info(fmt.Sprintf("starting engine loop for %s", n.Label))
 	e.Teardown.Add(1)
-	c := valuecomputers.MakeValueComputer(n, e.Logger)
+	c := valuecomputers.MakeValueComputer(n, e.Values, e.Logger)
 
 	if c == nil {
 		e.Logger.Error(fmt.Sprintf("failed to generate value computer for %s, quitting engine loop", opcnode.ToDebugString(&n)))
+		e.Teardown.Done()
 		return
 	}
 
@@ -49,23 +135,18 @@ func (e *valueChangeEngineImpl) executeEngineLoop(ctx context.Context, n opcnode
 		tickCount -= 1
 	}
 
-	d := delaycalculator.CreateNew(n.Waveform)
+	d := delaycalculator.CreateNew(n.Waveform, e.Clock)
+	q := qualitycalculator.CreateNew(n.Quality)
+	loopStart := e.Clock.Now()
+	e.restartFaults(n.Id, loopStart)
+	r := rand.New(rand.NewSource(loopStart.UnixNano()))
+	startingTickIndex := d.GetStartingTickIndex()
 	for {
-		for i := int64(0); i <= tickCount; i++ {
+		for i := startingTickIndex; i <= tickCount; i++ {
 			// emit value for current tick
 			t := i * int64(n.Waveform.TickFrequency)
-			v := (*c).GetValueAtTick(t)
-			e.debugWrite(t, v)
-			defer func() {
-				if r := recover(); r != nil {
-					e.Logger.Debug("attempted to push value change but event channel was closed")
-				}
-			}()
-			e.Logger.Debug(fmt.Sprintf("emitting new value %f for %s", v.GetValue(), opcnode.ToDebugString(&n)))
-			e.Events <- NodeValueChange{
-				Node:     n,
-				NewValue: v,
-			}
+			e.emitValue(n, t, (*c).GetValueAtTick(t), q.GetStatusAtTick(t),
+				n.SourceClock.Apply(d.GetCurrentTickTime(), e.Clock.Now().Sub(loopStart), r))
 
 			// wait for next tick
 			select {
@@ -76,22 +157,456 @@ func (e *valueChangeEngineImpl) executeEngineLoop(ctx context.Context, n opcnode
 			case <-time.After(d.GetDelayUntilNextTick()):
 			}
 		}
+		startingTickIndex = 0
+	}
+}
+
+func (e *valueChangeEngineImpl) executeTemplateLoop(ctx context.Context, n opcnode.OpcTemplateNode) {
+	e.Logger.Info(fmt.Sprintf("starting template loop for %s", n.Label))
+	e.Teardown.Add(1)
+	s := templatesimulators.MakeTemplateSimulator(n, e.Values, e.Logger)
+
+	if s == nil {
+		e.Logger.Error(fmt.Sprintf("failed to generate simulator for %s, quitting template loop", opcnode.ToDebugString(&n)))
+		e.Teardown.Done()
+		return
+	}
+
+	// signals are published by the template loop with good quality &
+	// the tick time, faults are injected into them like into any value node
+	signals := n.GetSignalNodes()
+
+	(*s).Init()
+	d := delaycalculator.CreateNew(waveform.Waveform{
+		Duration:      int64(n.TickFrequency),
+		TickFrequency: n.TickFrequency,
+		WaveformType:  waveform.Simulated,
+	}, e.Clock)
+	loopStart := e.Clock.Now()
+	for _, sn := range signals {
+		e.restartFaults(sn.Id, loopStart)
+	}
+	for {
+		t := e.Clock.Now().Sub(loopStart).Milliseconds()
+		for key, v := range (*s).Step() {
+			if sn, found := signals[key]; found {
+				e.emitValue(sn, t, v, quality.Good, d.GetCurrentTickTime())
+			}
+		}
+
+		// wait for next tick
+		select {
+		case <-ctx.Done():
+			e.Logger.Info(fmt.Sprintf("template loop done for %s", n.Label))
+			e.Teardown.Done()
+			return
+		case <-time.After(d.GetDelayUntilNextTick()):
+		}
+	}
+}
+
+// executeTriggerLoop applies the actions of fired triggers, apart from
+// the engine loops as actions may stop the loop that fired them
+func (e *valueChangeEngineImpl) executeTriggerLoop(ctx context.Context) {
+	e.Teardown.Add(1)
+	for {
+		select {
+		case <-ctx.Done():
+			e.Logger.Info("trigger loop done")
+			e.Teardown.Done()
+			return
+		case a := <-e.Actions:
+			if err := e.applyTriggerAction(a); err != nil {
+				e.Logger.Warn(fmt.Sprintf("failed to apply trigger action, reason: %v", err))
+			}
+		}
 	}
 }
 
+func (e *valueChangeEngineImpl) applyTriggerAction(a trigger.Action) error {
+	switch a.ActionType {
+	case trigger.StartWaveform:
+		return e.startNode(a.NodeId)
+	case trigger.StopWaveform:
+		return e.stopNode(a.NodeId)
+	case trigger.RestartWaveform:
+		if err := e.stopNode(a.NodeId); err != nil {
+			return err
+		}
+		return e.startNode(a.NodeId)
+	case trigger.SetValue:
+		return e.setNodeValue(a.NodeId, a.Value)
+	case trigger.StartScenario:
+		return e.Scenarios.Start(a.Scenario)
+	default:
+		return fmt.Errorf("unsupported action %d", a.ActionType)
+	}
+}
+
+func (e *valueChangeEngineImpl) fireTriggers(n opcnode.OpcValueNode, v waveformvalue.WaveformPointValue) {
+	for _, a := range e.Triggers.Evaluate(n.Id, v) {
+		select {
+		case e.Actions <- a:
+		default:
+			e.Logger.Warn(fmt.Sprintf("too many pending trigger actions, action fired by %s dropped", opcnode.ToDebugString(&n)))
+		}
+	}
+}
+
+func (e *valueChangeEngineImpl) emitValue(n opcnode.OpcValueNode, t int64, v waveformvalue.WaveformPointValue, status quality.StatusCode, timestamp time.Time) {
+	if e.Disabled.Contains(n.Id) {
+		return
+	}
+	f, found := e.getFaultInjector(n.Id)
+	if !found {
+		return
+	}
+	v, emit := f.Apply(v)
+	if !emit || e.isOverridden(n.Id) {
+		return
+	}
+
+	e.debugWrite(t, v)
+	e.Values.SetNodeValue(n.Id, v)
+	e.fireTriggers(n, v)
+	e.pushValue(n, v, status, timestamp)
+}
+
+func (e *valueChangeEngineImpl) pushValue(n opcnode.OpcValueNode, v waveformvalue.WaveformPointValue, status quality.StatusCode, timestamp time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.Logger.Debug("attempted to push value change but event channel was closed")
+		}
+	}()
+	e.Logger.Debug(fmt.Sprintf("emitting new value %v for %s", v.GetValue(), opcnode.ToDebugString(&n)))
+	c := NodeValueChange{
+		Node:      n,
+		NewValue:  v,
+		Status:    status,
+		Timestamp: timestamp,
+	}
+	e.Values.SetPublishedValue(c)
+	e.Events <- c
+}
+
 func (e *valueChangeEngineImpl) EventChannel() chan NodeValueChange {
 	return e.Events
 }
 
+func (e *valueChangeEngineImpl) InjectFault(id uuid.UUID, f fault.Fault) error {
+	i, found := e.getFaultInjector(id)
+	if !found {
+		return fmt.Errorf("value node %s not found", id)
+	}
+	i.Inject(f)
+	return nil
+}
+
+func (e *valueChangeEngineImpl) ClearFaults(id uuid.UUID) error {
+	i, found := e.getFaultInjector(id)
+	if !found {
+		return fmt.Errorf("value node %s not found", id)
+	}
+	i.Clear()
+	return nil
+}
+
+func (e *valueChangeEngineImpl) SubscribeWrites(c chan NodeValueWrite) {
+	for {
+		select {
+		case w := <-c:
+			// written values are visible to dependent nodes right away
+			if nodes := e.getNodes([]uuid.UUID{w.NodeId}); len(nodes) > 0 {
+				e.storeWrite(nodes[0], w.Value)
+			}
+			if o, found := e.getOverrideTracker(w.NodeId); found {
+				e.Logger.Info(fmt.Sprintf("overriding %s with %v", w.NodeId, w.Value.GetValue()))
+				o.Write(w.Value)
+			} else {
+				e.Logger.Debug(fmt.Sprintf("%s is not writable, value %v is kept until the next tick", w.NodeId, w.Value.GetValue()))
+			}
+		case <-e.Done:
+			return
+		}
+	}
+}
+
+func (e *valueChangeEngineImpl) SwitchWaveform(id uuid.UUID, w waveform.Waveform) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	i := e.indexOfNode(id)
+	if i < 0 {
+		return fmt.Errorf("value node %s not found", id)
+	}
+	n := e.Nodes[i]
+	if n.Waveform.WaveformType == waveform.Simulated || w.WaveformType == waveform.Simulated {
+		return fmt.Errorf("waveform of template signal %s cannot be switched", n.Label)
+	}
+	if n.Waveform.GetValueType() != w.GetValueType() {
+		return fmt.Errorf("new waveform of %s has a different value type", n.Label)
+	}
+	if err := w.Validate(); err != nil {
+		return fmt.Errorf("invalid waveform for %s: %w", n.Label, err)
+	}
+	if valuecomputers.MakeValueComputer(opcnode.OpcValueNode{Id: n.Id, Label: n.Label, Waveform: w}, e.Values, e.Logger) == nil {
+		return fmt.Errorf("new waveform of %s can not be simulated", n.Label)
+	}
+
+	// the old loop must be gone before the new one starts emitting
+	e.stopEngineLoop(id)
+	n.Waveform = w
+	e.Nodes[i] = n
+	if e.Context != nil && e.Context.Err() == nil {
+		e.startEngineLoop(e.Context, n)
+	}
+	e.Logger.Info(fmt.Sprintf("switched waveform of %s", opcnode.ToDebugString(&n)))
+	return nil
+}
+
+func (e *valueChangeEngineImpl) startNode(id uuid.UUID) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	n, err := e.getLoopNode(id)
+	if err != nil {
+		return err
+	}
+	if l, found := e.Loops[id]; found && l.isActive() {
+		return nil
+	}
+	if e.Context == nil || e.Context.Err() != nil {
+		return fmt.Errorf("engine not running")
+	}
+	e.startEngineLoop(e.Context, n)
+	return nil
+}
+
+// stopNode quits the loop of a value node, the node keeps its last value
+func (e *valueChangeEngineImpl) stopNode(id uuid.UUID) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if _, err := e.getLoopNode(id); err != nil {
+		return err
+	}
+	e.stopEngineLoop(id)
+	return nil
+}
+
+// stopEngineLoop cancels the loop of a value node & waits
+// for it to quit, the caller holds the lock
+func (e *valueChangeEngineImpl) stopEngineLoop(id uuid.UUID) {
+	if l, found := e.Loops[id]; found {
+		l.Cancel()
+		<-l.Done
+		delete(e.Loops, id)
+	}
+}
+
+// getLoopNode returns the value node with its own engine
+// loop by id, the caller holds the lock
+func (e *valueChangeEngineImpl) getLoopNode(id uuid.UUID) (opcnode.OpcValueNode, error) {
+	i := e.indexOfNode(id)
+	if i < 0 {
+		return opcnode.OpcValueNode{}, fmt.Errorf("value node %s not found", id)
+	}
+	if e.Nodes[i].Waveform.WaveformType == waveform.Simulated {
+		return opcnode.OpcValueNode{}, fmt.Errorf("%s is published by its template", e.Nodes[i].Label)
+	}
+	return e.Nodes[i], nil
+}
+
+// setNodeValue sets the value of a node as if it was written by a client,
+// the value is published & fires triggers like an emitted value
+func (e *valueChangeEngineImpl) setNodeValue(id uuid.UUID, f float64) error {
+	nodes := e.getNodes([]uuid.UUID{id})
+	if len(nodes) == 0 {
+		return fmt.Errorf("value node %s not found", id)
+	}
+	n := nodes[0]
+	v := makeValue(n.Waveform.GetValueType(), f)
+	e.storeWrite(n, v)
+	if o, found := e.getOverrideTracker(id); found {
+		o.Write(v)
+	}
+	if !e.Disabled.Contains(id) {
+		e.fireTriggers(n, v)
+		e.pushValue(n, v, quality.Good, e.Clock.Now())
+	}
+	return nil
+}
+
+// storeWrite makes a written value the current value of a node, it is kept
+// as pending write only for nodes that take written values into account
+func (e *valueChangeEngineImpl) storeWrite(n opcnode.OpcValueNode, v waveformvalue.WaveformPointValue) {
+	if n.Waveform.ConsumesWrites() {
+		e.Values.WriteNodeValue(n.Id, v)
+	} else {
+		e.Values.SetNodeValue(n.Id, v)
+	}
+}
+
+func (e *valueChangeEngineImpl) SetSpeed(f float64) error {
+	if err := e.Clock.SetSpeed(f); err != nil {
+		return err
+	}
+	e.Logger.Info(fmt.Sprintf("simulation speed set to %.2f", f))
+	return nil
+}
+
+// DisableNodes stops publishing the value nodes of a subtree, their
+// last value is republished once as out of service
+func (e *valueChangeEngineImpl) DisableNodes(id uuid.UUID) error {
+	ids := findSubtreeValueIds(e.Root, id)
+	if ids == nil {
+		return fmt.Errorf("node %s not found", id)
+	}
+	for _, n := range e.getNodes(ids) {
+		if e.Disabled.Contains(n.Id) {
+			continue
+		}
+		e.Disabled.Add(n.Id)
+		if v, found := e.Values.GetNodeValue(n.Id); found {
+			e.pushValue(n, v, quality.BadOutOfService, e.Clock.Now())
+		}
+	}
+	return nil
+}
+
+func (e *valueChangeEngineImpl) EnableNodes(id uuid.UUID) error {
+	ids := findSubtreeValueIds(e.Root, id)
+	if ids == nil {
+		return fmt.Errorf("node %s not found", id)
+	}
+	for _, i := range ids {
+		e.Disabled.Remove(i)
+	}
+	return nil
+}
+
+func (e *valueChangeEngineImpl) StartScenario(name string) error {
+	return e.Scenarios.Start(name)
+}
+
+func (e *valueChangeEngineImpl) StopScenario() error {
+	return e.Scenarios.Stop()
+}
+
+func (e *valueChangeEngineImpl) GetScenarios() []ScenarioStatus {
+	return e.Scenarios.GetStatus()
+}
+
+func (e *valueChangeEngineImpl) getNodes(ids []uuid.UUID) []opcnode.OpcValueNode {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	res := make([]opcnode.OpcValueNode, 0, len(ids))
+	for _, id := range ids {
+		if i := e.indexOfNode(id); i >= 0 {
+			res = append(res, e.Nodes[i])
+		}
+	}
+	return res
+}
+
+func (e *valueChangeEngineImpl) indexOfNode(id uuid.UUID) int {
+	for i, n := range e.Nodes {
+		if n.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *valueChangeEngineImpl) GetOverrides() []NodeOverride {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	res := make([]NodeOverride, 0)
+	for _, n := range e.Nodes {
+		if o, found := e.getOverrideTracker(n.Id); found {
+			if s := o.GetState(); s != nil {
+				res = append(res, NodeOverride{
+					Node:  n,
+					State: *s,
+				})
+			}
+		}
+	}
+	return res
+}
+
+func (e *valueChangeEngineImpl) ClearOverride(id uuid.UUID) error {
+	o, found := e.getOverrideTracker(id)
+	if !found {
+		return fmt.Errorf("writable value node %s not found", id)
+	}
+	return o.Clear()
+}
+
+func (e *valueChangeEngineImpl) isOverridden(id uuid.UUID) bool {
+	o, found := e.getOverrideTracker(id)
+	return found && o.IsActive()
+}
+
+func (e *valueChangeEngineImpl) getFaultInjector(id uuid.UUID) (faultinjector.FaultInjector, bool) {
+	e.registryLock.RLock()
+	defer e.registryLock.RUnlock()
+	i, found := e.Faults[id]
+	return i, found
+}
+
+// restartFaults makes the faults of a value node count from the start of its loop
+func (e *valueChangeEngineImpl) restartFaults(id uuid.UUID, loopStart time.Time) {
+	if f, found := e.getFaultInjector(id); found {
+		f.Restart(loopStart)
+	}
+}
+
+func (e *valueChangeEngineImpl) getOverrideTracker(id uuid.UUID) (overridetracker.OverrideTracker, bool) {
+	e.registryLock.RLock()
+	defer e.registryLock.RUnlock()
+	o, found := e.Overrides[id]
+	return o, found
+}
+
+// register sets up the faults & override of a value node, replacing
+// the ones of a previous version of the node
+func (e *valueChangeEngineImpl) register(n opcnode.OpcValueNode) {
+	e.registryLock.Lock()
+	defer e.registryLock.Unlock()
+	e.Faults[n.Id] = faultinjector.CreateNew(n.Faults, e.Clock, e.Logger.Named(n.Label))
+	delete(e.Overrides, n.Id)
+	if n.Override != nil {
+		e.Overrides[n.Id] = overridetracker.CreateNew(*n.Override)
+	}
+}
+
+func (e *valueChangeEngineImpl) unregister(id uuid.UUID) {
+	e.registryLock.Lock()
+	defer e.registryLock.Unlock()
+	delete(e.Faults, id)
+	delete(e.Overrides, id)
+}
+
 func (e *valueChangeEngineImpl) Stop() {
 	e.Logger.Info("stopping value change engine")
+	e.Scenarios.Stop()
 	if e.Cancel != nil {
 		e.Cancel()
 	}
+	close(e.Done)
 	close(e.Events)
 	e.Teardown.Wait()
 }
 
+func makeValue(t waveform.ValueType, f float64) waveformvalue.WaveformPointValue {
+	switch t {
+	case waveform.BooleanValue:
+		return &waveformvalue.Transition{Value: f != 0}
+	case waveform.IntegerValue:
+		return &waveformvalue.IntegerValue{Value: int32(math.Round(f))}
+	default:
+		return &waveformvalue.DoubleValue{Value: f}
+	}
+}
+
 func (e *valueChangeEngineImpl) debugWrite(t int64, v waveformvalue.WaveformPointValue) {
 	if !e.DebugEnabled {
 		return
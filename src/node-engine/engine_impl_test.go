@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -14,6 +15,10 @@ import (
 	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
 	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
 	"github.com/google/uuid"
@@ -24,6 +29,8 @@ import (
 type Sample struct {
 	timestamp time.Time
 	value     waveformvalue.WaveformPointValue
+	status    quality.StatusCode
+	source    time.Time
 }
 
 type ResultSet struct {
@@ -69,6 +76,8 @@ func (c *SampleCollector) Subscribe(e nodeengine.ValueChangeEngine) {
 			s := Sample{
 				timestamp: time.Now(),
 				value:     v.NewValue,
+				status:    v.Status,
+				source:    v.Timestamp,
 			}
 
 			r, ok := c.Acc[v.Node.Id]
@@ -659,6 +668,451 @@ func TestSingleNumericNodeValues_CubicSplineSmoothing_WaveformDuration2700Ms_Col
 	assertNumericSamplesets(t, expectedSamples.samples, numericSamples, wiggle)
 }
 
+func TestSingleNumericNodeValues_WriteToWritableNode_SimulationSuspendedWhileHeld(t *testing.T) {
+	// arrange
+	l := zaptest.NewLogger(t)
+	var m waveform.WaveformMeta = waveform.NumericWaveformMeta{
+		Smoothing: waveform.Step,
+	}
+	n := &opcnode.OpcValueNode{
+		Id:    uuid.MustParse("da858518-50c9-4e55-b312-6370275b412d"),
+		Label: "Numbers",
+		Waveform: waveform.Waveform{
+			Duration:      1000,
+			TickFrequency: 100,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &m,
+			TransitionPoints: []waveform.WaveformValue{
+				{
+					Tick: 0,
+					Value: &waveformvalue.DoubleValue{
+						Value: 1.0,
+					},
+				},
+			},
+		},
+		Override: &override.OverrideBehavior{
+			Mode:     override.Hold,
+			Duration: 300,
+		},
+	}
+	s := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    uuid.New(),
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				n,
+			},
+		},
+	}
+	e := nodeengine.CreateNew(s, l, false)
+	c := SampleCollector{}
+	writes := make(chan nodeengine.NodeValueWrite, 1)
+	go e.SubscribeWrites(writes)
+	time.AfterFunc(time.Duration(250)*time.Millisecond, func() {
+		writes <- nodeengine.NodeValueWrite{
+			NodeId: n.Id,
+			Value:  &waveformvalue.DoubleValue{Value: 5.0},
+		}
+	})
+
+	// act
+	testStart := time.Now()
+	nodeSamples := c.CollectSamples(context.TODO(), e, time.Duration(850)*time.Millisecond)
+
+	// assert
+	numericSamples := nodeSamples[n.Id].samples
+	expectedSamples := makeExpectedNumericResultSet(map[int]float64{
+		0:   1.0,
+		100: 1.0,
+		200: 1.0,
+		600: 1.0,
+		700: 1.0,
+		800: 1.0,
+	})
+
+	wiggle := time.Duration(20) * time.Millisecond
+	adjustExpectedTimestamps(&expectedSamples, testStart)
+	printSamples(l, expectedSamples.samples, testStart)
+	printSamples(l, numericSamples, testStart)
+	assertNumericSamplesets(t, expectedSamples.samples, numericSamples, wiggle)
+}
+
+func TestScenario_SwitchWaveformAndDisableNode_AppliedAtScenarioTime(t *testing.T) {
+	// arrange
+	l := zaptest.NewLogger(t)
+	var m waveform.WaveformMeta = waveform.NumericWaveformMeta{
+		Smoothing: waveform.Step,
+	}
+	makeWaveform := func(v float64) waveform.Waveform {
+		return waveform.Waveform{
+			Duration:      1000,
+			TickFrequency: 100,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &m,
+			TransitionPoints: []waveform.WaveformValue{
+				{
+					Tick: 0,
+					Value: &waveformvalue.DoubleValue{
+						Value: v,
+					},
+				},
+			},
+		}
+	}
+	switched := &opcnode.OpcValueNode{
+		Id:       uuid.MustParse("3c5e7a9c-1e3a-4c5e-9a1c-3e5a7c9e1a3c"),
+		Label:    "Switched",
+		Waveform: makeWaveform(1.0),
+	}
+	disabled := &opcnode.OpcValueNode{
+		Id:       uuid.MustParse("7a9c1e3a-5c7e-4a9c-8e3a-5c7e9a1c3e5a"),
+		Label:    "Disabled",
+		Waveform: makeWaveform(2.0),
+	}
+	newWaveform := makeWaveform(5.0)
+	s := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    uuid.New(),
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				switched,
+				disabled,
+			},
+		},
+		Scenarios: []scenario.Scenario{
+			{
+				Name: "trip",
+				Actions: []scenario.Action{
+					{
+						At:         450,
+						ActionType: scenario.SwitchWaveform,
+						NodeId:     switched.Id,
+						Waveform:   &newWaveform,
+					},
+					{
+						At:         450,
+						ActionType: scenario.DisableNodes,
+						NodeId:     disabled.Id,
+					},
+				},
+			},
+		},
+	}
+	e := nodeengine.CreateNew(s, l, false)
+	c := SampleCollector{}
+	if err := e.StartScenario("trip"); err != nil {
+		t.Errorf("failed to start scenario: %v", err)
+		t.FailNow()
+	}
+
+	// act
+	nodeSamples := c.CollectSamples(context.TODO(), e, time.Duration(900)*time.Millisecond)
+
+	// assert
+	switchedSamples := nodeSamples[switched.Id].samples
+	if len(switchedSamples) != 10 {
+		t.Errorf("expected 10 samples of the switched node and got %d", len(switchedSamples))
+		t.FailNow()
+	}
+	for i, sample := range switchedSamples {
+		expected := 1.0
+		if i >= 5 {
+			expected = 5.0
+		}
+		if sample.value.GetValue() != expected {
+			t.Errorf("expected sample %d of the switched node to be %f, actual: %v", i+1, expected, sample.value.GetValue())
+		}
+	}
+
+	disabledSamples := nodeSamples[disabled.Id].samples
+	if len(disabledSamples) != 6 {
+		t.Errorf("expected 6 samples of the disabled node and got %d", len(disabledSamples))
+		t.FailNow()
+	}
+	if last := disabledSamples[5]; last.value.GetValue() != 2.0 || last.status != quality.BadOutOfService {
+		t.Errorf("expected the disabled node to republish 2.0 out of service, actual: %v (%v)", last.value.GetValue(), last.status)
+	}
+}
+
+func TestTrigger_SourceBecomesTrue_StopsTargetAndSetsValue(t *testing.T) {
+	// arrange
+	l := zaptest.NewLogger(t)
+	var m waveform.WaveformMeta = waveform.NumericWaveformMeta{
+		Smoothing: waveform.Step,
+	}
+	source := &opcnode.OpcValueNode{
+		Id:    uuid.MustParse("1b3d5f7a-9c1e-4b3d-8f7a-9c1e3b5d7f9a"),
+		Label: "Trip",
+		Waveform: waveform.Waveform{
+			Duration:      2000,
+			TickFrequency: 100,
+			WaveformType:  waveform.Transitions,
+			TransitionPoints: []waveform.WaveformValue{
+				{
+					Tick:  300,
+					Value: &waveformvalue.Transition{},
+				},
+			},
+		},
+	}
+	target := &opcnode.OpcValueNode{
+		Id:    uuid.MustParse("5f7a9c1e-3b5d-4f7a-9c1e-3b5d7f9a1c3e"),
+		Label: "Pump",
+		Waveform: waveform.Waveform{
+			Duration:      2000,
+			TickFrequency: 200,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &m,
+			TransitionPoints: []waveform.WaveformValue{
+				{
+					Tick: 0,
+					Value: &waveformvalue.DoubleValue{
+						Value: 1.0,
+					},
+				},
+			},
+		},
+	}
+	s := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    uuid.New(),
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				source,
+				target,
+			},
+		},
+		Triggers: []trigger.Trigger{
+			{
+				Source:    source.Id,
+				Condition: trigger.BecomesTrue,
+				Actions: []trigger.Action{
+					{
+						ActionType: trigger.StopWaveform,
+						NodeId:     target.Id,
+					},
+					{
+						ActionType: trigger.SetValue,
+						NodeId:     target.Id,
+						Value:      7.0,
+					},
+				},
+			},
+		},
+	}
+	e := nodeengine.CreateNew(s, l, false)
+	c := SampleCollector{}
+
+	// act
+	testStart := time.Now()
+	nodeSamples := c.CollectSamples(context.TODO(), e, time.Duration(900)*time.Millisecond)
+
+	// assert
+	samples := nodeSamples[target.Id].samples
+	if len(samples) != 3 {
+		t.Errorf("expected 3 samples of the target node and got %d", len(samples))
+		t.FailNow()
+	}
+	for i, expected := range []float64{1.0, 1.0, 7.0} {
+		if samples[i].value.GetValue() != expected {
+			t.Errorf("expected sample %d of the target node to be %f, actual: %v", i+1, expected, samples[i].value.GetValue())
+		}
+	}
+	wiggle := time.Duration(20) * time.Millisecond
+	if expected := testStart.Add(300 * time.Millisecond); !areClose(expected, samples[2].timestamp, wiggle) {
+		t.Errorf("expected the value to be set on %s, actual: %s", formatDate(expected), formatDate(samples[2].timestamp))
+	}
+}
+
+func TestReconfigure_UpdatedAndAddedNodes_OnlyAffectedLoopsRestarted(t *testing.T) {
+	// arrange
+	l := zaptest.NewLogger(t)
+	var m waveform.WaveformMeta = waveform.NumericWaveformMeta{
+		Smoothing: waveform.Step,
+	}
+	makeNode := func(id string, label string, v float64) *opcnode.OpcValueNode {
+		return &opcnode.OpcValueNode{
+			Id:    uuid.MustParse(id),
+			Label: label,
+			Waveform: waveform.Waveform{
+				Duration:      1000,
+				TickFrequency: 100,
+				WaveformType:  waveform.NumericValues,
+				Meta:          &m,
+				TransitionPoints: []waveform.WaveformValue{
+					{
+						Tick: 0,
+						Value: &waveformvalue.DoubleValue{
+							Value: v,
+						},
+					},
+				},
+			},
+		}
+	}
+	kept := makeNode("2a4c6e8a-0c2e-4a6c-8e0a-2c4e6a8c0e2a", "Kept", 1.0)
+	updated := makeNode("4c6e8a0c-2e4a-4c8e-8a2c-4e6a8c0e2a4c", "Updated", 2.0)
+	added := makeNode("6e8a0c2e-4a6c-4e0a-8c4e-6a8c0e2a4c6e", "Added", 3.0)
+	rootId := uuid.New()
+	s := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    rootId,
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				kept,
+				updated,
+			},
+		},
+	}
+	newStructure := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    rootId,
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				makeNode(kept.Id.String(), "Kept", 1.0),
+				makeNode(updated.Id.String(), "Updated", 5.0),
+				added,
+			},
+		},
+	}
+	d := opc.Diff(s, newStructure)
+	if len(d.Removed) != 0 || len(d.Added) != 1 || len(d.Updated) != 1 {
+		t.Errorf("expected 1 added & 1 updated node, actual: %d removed, %d added & %d updated", len(d.Removed), len(d.Added), len(d.Updated))
+		t.FailNow()
+	}
+	e := nodeengine.CreateNew(s, l, false)
+	c := SampleCollector{}
+	go func() {
+		time.Sleep(time.Duration(450) * time.Millisecond)
+		e.Reconfigure(newStructure, d)
+	}()
+
+	// act
+	testStart := time.Now()
+	nodeSamples := c.CollectSamples(context.TODO(), e, time.Duration(850)*time.Millisecond)
+
+	// assert
+	wiggle := time.Duration(30) * time.Millisecond
+	reconfigured := testStart.Add(time.Duration(450) * time.Millisecond)
+	keptSamples := nodeSamples[kept.Id].samples
+	if len(keptSamples) < 8 {
+		t.Errorf("expected at least 8 samples of Kept and got %d", len(keptSamples))
+	}
+	for i, sample := range keptSamples {
+		if sample.value.GetValue() != 1.0 {
+			t.Errorf("expected sample %d of Kept to be 1, actual: %v", i+1, sample.value.GetValue())
+		}
+		if areClose(sample.timestamp, reconfigured, wiggle) {
+			t.Errorf("expected the loop of Kept not to be restarted, got sample %d on %s", i+1, formatDate(sample.timestamp))
+		}
+	}
+
+	// the updated loop is restarted with the new waveform
+	updatedSamples := nodeSamples[updated.Id].samples
+	switchedAt := -1
+	for i, sample := range updatedSamples {
+		if sample.value.GetValue() == 5.0 && switchedAt < 0 {
+			switchedAt = i
+		}
+		expected := 2.0
+		if switchedAt >= 0 {
+			expected = 5.0
+		}
+		if sample.value.GetValue() != expected {
+			t.Errorf("expected sample %d of Updated to be %f, actual: %v", i+1, expected, sample.value.GetValue())
+		}
+	}
+	if switchedAt != 5 || !areClose(updatedSamples[switchedAt].timestamp, reconfigured, wiggle) {
+		t.Errorf("expected 5 samples of 2 & Updated to restart on %s, actual: %d samples", formatDate(reconfigured), switchedAt)
+		t.FailNow()
+	}
+
+	addedSamples := nodeSamples[added.Id].samples
+	if len(addedSamples) < 3 || !areClose(addedSamples[0].timestamp, reconfigured, wiggle) {
+		t.Errorf("expected Added to start on %s with at least 3 samples, actual: %d samples", formatDate(reconfigured), len(addedSamples))
+		t.FailNow()
+	}
+	for i, sample := range addedSamples {
+		if sample.value.GetValue() != 3.0 {
+			t.Errorf("expected sample %d of Added to be 3, actual: %v", i+1, sample.value.GetValue())
+		}
+	}
+}
+
+func TestStatus_NodesDisabled_EngineIdleWithLastValuesOutOfService(t *testing.T) {
+	// arrange
+	l := zaptest.NewLogger(t)
+	var m waveform.WaveformMeta = waveform.NumericWaveformMeta{
+		Smoothing: waveform.Step,
+	}
+	n := &opcnode.OpcValueNode{
+		Id:    uuid.MustParse("7c9e1a3c-5e7a-4c9e-9a3c-5e7a9c1e3a5c"),
+		Label: "Level",
+		Waveform: waveform.Waveform{
+			Duration:      1000,
+			TickFrequency: 100,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &m,
+			TransitionPoints: []waveform.WaveformValue{
+				{
+					Tick: 0,
+					Value: &waveformvalue.DoubleValue{
+						Value: 4.0,
+					},
+				},
+			},
+		},
+	}
+	rootId := uuid.New()
+	s := opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    rootId,
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				n,
+			},
+		},
+	}
+	e := nodeengine.CreateNew(s, l, false)
+	c := SampleCollector{}
+	var before, after nodeengine.EngineStatus
+	var values []nodeengine.NodeValue
+	var unknownErr error
+	go func() {
+		time.Sleep(time.Duration(350) * time.Millisecond)
+		before = e.GetStatus()
+		e.DisableNodes(rootId)
+		after = e.GetStatus()
+		values, _ = e.GetValues([]uuid.UUID{n.Id})
+		_, unknownErr = e.GetValues([]uuid.UUID{uuid.New()})
+	}()
+
+	// act
+	c.CollectSamples(context.TODO(), e, time.Duration(500)*time.Millisecond)
+
+	// assert
+	if !before.Running || before.Idle || len(before.Nodes) != 1 || !before.Nodes[0].Active {
+		t.Errorf("expected the engine to be running & simulating Level, actual: %+v", before)
+	}
+	if before.Speed != 1 || before.Uptime < time.Duration(300)*time.Millisecond {
+		t.Errorf("expected speed 1 & uptime of at least 300ms, actual: %f & %v", before.Speed, before.Uptime)
+	}
+	if !after.Running || !after.Idle || !after.Nodes[0].Disabled {
+		t.Errorf("expected the engine to be idle with Level disabled, actual: %+v", after)
+	}
+	if len(values) != 1 || values[0].Value == nil || values[0].Value.GetValue() != 4.0 {
+		t.Errorf("expected the last value of Level to be 4, actual: %+v", values)
+		t.FailNow()
+	}
+	if values[0].Status != quality.BadOutOfService || values[0].Timestamp == nil {
+		t.Errorf("expected the last value of Level to be out of service, actual status: %v", values[0].Status)
+	}
+	if unknownErr == nil {
+		t.Errorf("expected an error getting the value of an unknown node")
+	}
+}
+
 func areClose(t1, t2 time.Time, wiggleRoom time.Duration) bool {
 	diff := t1.Sub(t2)
 	return diff <= wiggleRoom && diff >= -wiggleRoom
@@ -764,6 +1218,30 @@ func loadExpectedNumericResultSet(f string) (ResultSet, error) {
 	return r, nil
 }
 
+func makeExpectedNumericResultSet(v map[int]float64) ResultSet {
+	r := ResultSet{
+		samples: make([]Sample, 0),
+	}
+
+	offsets := make([]int, 0, len(v))
+	for o := range v {
+		offsets = append(offsets, o)
+	}
+	sort.Ints(offsets)
+
+	for _, o := range offsets {
+		var t time.Time
+		r.samples = append(r.samples, Sample{
+			timestamp: t.Add(time.Duration(o) * time.Millisecond),
+			value: &waveformvalue.DoubleValue{
+				Value: v[o],
+			},
+		})
+	}
+
+	return r
+}
+
 func adjustExpectedTimestamps(r *ResultSet, o time.Time) {
 	var t time.Time
 	for i := range r.samples {
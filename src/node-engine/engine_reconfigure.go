@@ -0,0 +1,89 @@
+package nodeengine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+)
+
+// Reconfigure applies the changes of the structure in place, only the
+// loops of the removed, added & updated nodes are stopped or started
+func (e *valueChangeEngineImpl) Reconfigure(s opc.OpcStructure, d opc.StructureDiff) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, c := range d.Removed {
+		e.removeSubtree(c.Node)
+	}
+	for _, c := range d.Updated {
+		if n, ok := c.Node.(*opcnode.OpcValueNode); ok {
+			e.updateNode(*n)
+		}
+	}
+	for _, c := range d.Added {
+		e.addSubtree(c.Node)
+	}
+	e.Root = s.Root
+	e.Scenarios.SetScenarios(s.Scenarios)
+	e.Triggers.SetTriggers(s.Triggers)
+	e.Logger.Info(fmt.Sprintf("reconfigured engine, %d subtrees removed, %d added & %d nodes updated",
+		len(d.Removed), len(d.Added), len(d.Updated)))
+}
+
+// removeSubtree stops the loops of the nodes of the subtree
+// & forgets about them, the caller holds the lock
+func (e *valueChangeEngineImpl) removeSubtree(r opcnode.OpcStructureNode) {
+	subtree := opcnode.OpcContainerNode{Children: []opcnode.OpcStructureNode{r}}
+	for _, t := range extractTemplateNodes(subtree) {
+		e.stopEngineLoop(t.Id)
+		e.Templates = slices.DeleteFunc(e.Templates, func(m opcnode.OpcTemplateNode) bool {
+			return m.Id == t.Id
+		})
+	}
+	for _, n := range extractValueNodes(subtree) {
+		e.stopEngineLoop(n.Id)
+		if i := e.indexOfNode(n.Id); i >= 0 {
+			e.Nodes = slices.Delete(e.Nodes, i, i+1)
+		}
+		e.unregister(n.Id)
+		e.Disabled.Remove(n.Id)
+	}
+}
+
+// addSubtree starts the loops of the nodes of the subtree, the caller holds the lock
+func (e *valueChangeEngineImpl) addSubtree(r opcnode.OpcStructureNode) {
+	subtree := opcnode.OpcContainerNode{Children: []opcnode.OpcStructureNode{r}}
+	running := e.Context != nil && e.Context.Err() == nil
+	for _, n := range extractValueNodes(subtree) {
+		e.register(n)
+		e.Nodes = append(e.Nodes, n)
+		if running && n.Waveform.WaveformType != waveform.Simulated {
+			e.startEngineLoop(e.Context, n)
+		}
+	}
+	for _, t := range extractTemplateNodes(subtree) {
+		e.Templates = append(e.Templates, t)
+		if running {
+			e.startTemplateLoop(e.Context, t)
+		}
+	}
+}
+
+// updateNode restarts the loop of a value node with its new
+// behavior, the caller holds the lock
+func (e *valueChangeEngineImpl) updateNode(n opcnode.OpcValueNode) {
+	i := e.indexOfNode(n.Id)
+	if i < 0 {
+		e.Logger.Warn(fmt.Sprintf("updated node %s not found", opcnode.ToDebugString(&n)))
+		return
+	}
+	_, running := e.Loops[n.Id]
+	e.stopEngineLoop(n.Id)
+	e.register(n)
+	e.Nodes[i] = n
+	if running && e.Context != nil && e.Context.Err() == nil {
+		e.startEngineLoop(e.Context, n)
+	}
+}
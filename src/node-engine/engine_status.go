@@ -0,0 +1,137 @@
+package nodeengine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
+)
+
+// NodeValue is the last value published for a value node,
+// Value & Timestamp are nil until the node publishes its first value
+type NodeValue struct {
+	Node      opcnode.OpcValueNode
+	Value     waveformvalue.WaveformPointValue
+	Status    quality.StatusCode
+	Timestamp *time.Time
+}
+
+// NodeStatus describes the health of a value node; Active is false
+// once its simulation was stopped, e.g. by a trigger
+type NodeStatus struct {
+	NodeValue
+	Active     bool
+	Disabled   bool
+	Overridden bool
+	Faults     []fault.FaultType
+}
+
+// EngineStatus is a snapshot of the simulation, the engine is idle
+// while running with every node stopped or disabled; there is
+// nothing to resume, it stops being idle once a node is started or enabled
+type EngineStatus struct {
+	Running bool
+	Idle    bool
+	Speed   float64
+	Uptime  time.Duration
+	Nodes   []NodeStatus
+}
+
+// GetValues returns the last published value of the given
+// value nodes, or of every value node if no id is given
+func (e *valueChangeEngineImpl) GetValues(ids []uuid.UUID) ([]NodeValue, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(ids) == 0 {
+		res := make([]NodeValue, len(e.Nodes))
+		for i, n := range e.Nodes {
+			res[i] = e.getNodeValue(n)
+		}
+		return res, nil
+	}
+
+	res := make([]NodeValue, len(ids))
+	for i, id := range ids {
+		idx := e.indexOfNode(id)
+		if idx < 0 {
+			return nil, fmt.Errorf("value node %s not found", id)
+		}
+		res[i] = e.getNodeValue(e.Nodes[idx])
+	}
+	return res, nil
+}
+
+func (e *valueChangeEngineImpl) GetStatus() EngineStatus {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	s := EngineStatus{
+		Running: e.isRunning(),
+		Speed:   e.Clock.GetSpeed(),
+		Nodes:   make([]NodeStatus, len(e.Nodes)),
+	}
+	if s.Running {
+		s.Uptime = time.Since(e.Started)
+	}
+
+	// simulated nodes are published by the loop of their template
+	loopIds := make(map[uuid.UUID]uuid.UUID)
+	for _, t := range e.Templates {
+		for _, id := range collectValueIds(opcnode.OpcContainerNode{Children: t.Children}) {
+			loopIds[id] = t.Id
+		}
+	}
+
+	simulating := false
+	for i, n := range e.Nodes {
+		loopId := n.Id
+		if n.Waveform.WaveformType == waveform.Simulated {
+			loopId = loopIds[n.Id]
+		}
+		l, found := e.Loops[loopId]
+		ns := NodeStatus{
+			NodeValue:  e.getNodeValue(n),
+			Active:     found && l.isActive(),
+			Disabled:   e.Disabled.Contains(n.Id),
+			Overridden: e.isOverridden(n.Id),
+			Faults:     make([]fault.FaultType, 0),
+		}
+		if f, found := e.getFaultInjector(n.Id); found {
+			for _, a := range f.GetActiveFaults() {
+				ns.Faults = append(ns.Faults, a.FaultType)
+			}
+		}
+		simulating = simulating || (ns.Active && !ns.Disabled)
+		s.Nodes[i] = ns
+	}
+	s.Idle = s.Running && len(e.Nodes) > 0 && !simulating
+	return s
+}
+
+func (e *valueChangeEngineImpl) getNodeValue(n opcnode.OpcValueNode) NodeValue {
+	v := NodeValue{Node: n}
+	if c, found := e.Values.GetPublishedValue(n.Id); found {
+		v.Value = c.NewValue
+		v.Status = c.Status
+		v.Timestamp = &c.Timestamp
+	}
+	return v
+}
+
+// isRunning tells whether the engine was started & not stopped yet,
+// the caller holds the lock
+func (e *valueChangeEngineImpl) isRunning() bool {
+	if e.Context == nil {
+		return false
+	}
+	select {
+	case <-e.Done:
+		return false
+	default:
+		return true
+	}
+}
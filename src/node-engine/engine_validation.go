@@ -0,0 +1,39 @@
+package nodeengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	templatesimulators "github.com/AndreiLacatos/opc-engine/node-engine/template_simulators"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"go.uber.org/zap"
+)
+
+// Validate checks the structure & makes sure a value computer or simulator
+// can be made for each of its nodes, nothing is started
+func Validate(s opc.OpcStructure) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	l := zap.NewNop()
+	r := newValueStore()
+	for _, n := range extractValueNodes(s.Root) {
+		if n.Waveform.WaveformType == waveform.Simulated {
+			continue
+		}
+		if valuecomputers.MakeValueComputer(n, r, l) == nil {
+			errs = append(errs, fmt.Errorf("value node %s can not be simulated", opcnode.ToDebugString(&n)))
+		}
+	}
+	for _, t := range extractTemplateNodes(s.Root) {
+		if templatesimulators.MakeTemplateSimulator(t, r, l) == nil {
+			errs = append(errs, fmt.Errorf("template %s can not be simulated", opcnode.ToDebugString(&t)))
+		}
+	}
+	return errors.Join(errs...)
+}
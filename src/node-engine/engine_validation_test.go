@@ -0,0 +1,78 @@
+package nodeengine_test
+
+import (
+	"strings"
+	"testing"
+
+	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestValidate(t *testing.T) {
+	var step waveform.WaveformMeta = waveform.NumericWaveformMeta{Smoothing: waveform.Step}
+	valueNode := &opcnode.OpcValueNode{
+		Id:    uuid.New(),
+		Label: "Level",
+		Waveform: waveform.Waveform{
+			Duration:      1000,
+			TickFrequency: 100,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &step,
+		},
+	}
+	for _, tc := range []struct {
+		name  string
+		nodes []opcnode.OpcStructureNode
+		err   string
+	}{
+		{"simulated value node", []opcnode.OpcStructureNode{valueNode}, ""},
+		{"template without definition", []opcnode.OpcStructureNode{&opcnode.OpcTemplateNode{Id: uuid.New(), Label: "Tank", TickFrequency: 100}}, "can not be simulated"},
+		{"structure problems first", []opcnode.OpcStructureNode{valueNode, valueNode}, "duplicate node id"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := nodeengine.Validate(opc.OpcStructure{
+				Root: opcnode.OpcContainerNode{Id: uuid.New(), Label: "Root", Children: tc.nodes},
+			})
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("expected the structure to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected an error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestSwitchWaveform_WaveformWithoutTickFrequency_Rejected(t *testing.T) {
+	var step waveform.WaveformMeta = waveform.NumericWaveformMeta{Smoothing: waveform.Step}
+	n := &opcnode.OpcValueNode{
+		Id:    uuid.New(),
+		Label: "Level",
+		Waveform: waveform.Waveform{
+			Duration:      1000,
+			TickFrequency: 100,
+			WaveformType:  waveform.NumericValues,
+			Meta:          &step,
+		},
+	}
+	e := nodeengine.CreateNew(opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{Id: uuid.New(), Label: "Root", Children: []opcnode.OpcStructureNode{n}},
+	}, zap.NewNop(), false)
+
+	err := e.SwitchWaveform(n.Id, waveform.Waveform{
+		Duration:     1000,
+		WaveformType: waveform.NumericValues,
+		Meta:         &step,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "no tick frequency") {
+		t.Errorf("expected the waveform to be rejected, got %v", err)
+	}
+}
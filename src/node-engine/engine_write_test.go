@@ -0,0 +1,93 @@
+package nodeengine
+
+import (
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	levelId = uuid.MustParse("4b7e2f0a-8c1d-4e3f-a5b6-7c8d9e0f1a2b")
+	totalId = uuid.MustParse("9d3c5a7e-1f2b-4c6d-8e0f-a1b2c3d4e5f6")
+)
+
+// makeWriteEngine makes an engine, without starting it, with a level
+// node firing a trigger & a totalizer of the level taking client writes
+func makeWriteEngine(t *testing.T) *valueChangeEngineImpl {
+	var step waveform.WaveformMeta = waveform.NumericWaveformMeta{Smoothing: waveform.Step}
+	var total waveform.WaveformMeta = waveform.TotalizerWaveformMeta{Source: levelId, Scale: 1}
+	e := CreateNew(opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    uuid.MustParse("3d4e5f6a-7b8c-4d9e-8f1a-2b3c4d5e6f7a"),
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				&opcnode.OpcValueNode{
+					Id:       levelId,
+					Label:    "Level",
+					Waveform: waveform.Waveform{Duration: 1000, TickFrequency: 100, WaveformType: waveform.NumericValues, Meta: &step},
+				},
+				&opcnode.OpcValueNode{
+					Id:       totalId,
+					Label:    "Total",
+					Waveform: waveform.Waveform{Duration: 1000, TickFrequency: 100, WaveformType: waveform.Totalizer, Meta: &total},
+				},
+			},
+		},
+		Triggers: []trigger.Trigger{{
+			Source:    levelId,
+			Condition: trigger.RisesAbove,
+			Threshold: 5,
+			Actions:   []trigger.Action{{ActionType: trigger.StopWaveform, NodeId: totalId}},
+		}},
+	}, zap.NewNop(), false).(*valueChangeEngineImpl)
+	go func() {
+		for range e.Events {
+		}
+	}()
+	t.Cleanup(func() { close(e.Events) })
+	return e
+}
+
+func TestSetNodeValue_FiresTriggers(t *testing.T) {
+	e := makeWriteEngine(t)
+
+	for _, v := range []float64{1, 10} {
+		if err := e.setNodeValue(levelId, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case a := <-e.Actions:
+		if a.ActionType != trigger.StopWaveform || a.NodeId != totalId {
+			t.Errorf("expected the trigger to stop the totalizer, got %+v", a)
+		}
+	default:
+		t.Error("expected the set value to fire the trigger")
+	}
+}
+
+func TestSetNodeValue_KeepsPendingWriteOnlyForNodesTakingWrites(t *testing.T) {
+	e := makeWriteEngine(t)
+
+	for _, id := range []uuid.UUID{levelId, totalId} {
+		if err := e.setNodeValue(id, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if v, _ := e.Values.GetNodeValue(levelId); v == nil || v.GetValue() != 3.0 {
+		t.Errorf("expected the set value to be the current value, got %v", v)
+	}
+	if _, found := e.Values.TakeNodeWrite(levelId); found {
+		t.Error("expected no pending write for a node computed from its waveform only")
+	}
+	if _, found := e.Values.TakeNodeWrite(totalId); !found {
+		t.Error("expected a pending write for the totalizer")
+	}
+}
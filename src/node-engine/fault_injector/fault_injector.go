@@ -0,0 +1,27 @@
+package faultinjector
+
+import (
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	"go.uber.org/zap"
+)
+
+type FaultInjector interface {
+	init([]fault.Fault, simulationclock.SimulationClock, *zap.Logger)
+	Restart(time.Time)
+	Apply(waveformvalue.WaveformPointValue) (waveformvalue.WaveformPointValue, bool)
+	Inject(fault.Fault)
+	Clear()
+	GetActiveFaults() []fault.Fault
+}
+
+// CreateNew makes a fault injector timing the faults on the simulated
+// time of the given clock, without a clock faults follow the wall clock
+func CreateNew(f []fault.Fault, c simulationclock.SimulationClock, l *zap.Logger) FaultInjector {
+	i := faultInjectorImpl{}
+	i.init(f, c, l)
+	return &i
+}
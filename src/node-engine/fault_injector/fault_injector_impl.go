@@ -0,0 +1,174 @@
+package faultinjector
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	"go.uber.org/zap"
+)
+
+type faultInjectorImpl struct {
+	logger    *zap.Logger
+	clock     simulationclock.SimulationClock
+	lock      sync.Mutex
+	scheduled []fault.Fault
+	injected  []fault.Fault
+	origin    *time.Time
+	held      waveformvalue.WaveformPointValue
+}
+
+func (i *faultInjectorImpl) init(f []fault.Fault, c simulationclock.SimulationClock, l *zap.Logger) {
+	i.logger = l
+	i.clock = c
+	if i.clock == nil {
+		i.clock = simulationclock.CreateNew()
+	}
+	i.scheduled = f
+	i.injected = make([]fault.Fault, 0)
+}
+
+// Restart makes the scheduled faults count from the given start of the
+// engine loop, on demand faults keep the rest of their duration
+func (i *faultInjectorImpl) Restart(origin time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.origin != nil {
+		shift := origin.Sub(*i.origin).Milliseconds()
+		for k := range i.injected {
+			i.injected[k].Start -= shift
+		}
+	}
+	i.origin = &origin
+}
+
+func (i *faultInjectorImpl) Apply(v waveformvalue.WaveformPointValue) (waveformvalue.WaveformPointValue, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	t := i.getElapsed()
+	flatline := false
+	for _, f := range i.getActiveFaults(t) {
+		switch f.FaultType {
+		case fault.DroppedUpdates:
+			return v, false
+		case fault.Flatline:
+			if i.held != nil {
+				v = i.held
+			}
+			flatline = true
+		case fault.StuckAt:
+			v = makeValue(v, f.Value, f.Value != 0)
+		case fault.Offset:
+			v = makeNumericValue(v, func(x float64) float64 { return x + f.Value })
+		case fault.Spike:
+			// the spike peaks at the start of the window & decays linearly
+			elapsed := f.GetElapsedAt(t)
+			magnitude := f.Value * (1 - float64(elapsed)/float64(f.Duration))
+			v = makeNumericValue(v, func(x float64) float64 { return x + magnitude })
+		case fault.NotANumber:
+			v = makeNumericValue(v, func(float64) float64 { return math.NaN() })
+		case fault.Infinity:
+			sign := 1
+			if f.Value < 0 {
+				sign = -1
+			}
+			v = makeNumericValue(v, func(float64) float64 { return math.Inf(sign) })
+		}
+	}
+
+	if !flatline {
+		i.held = v
+	}
+	return v, true
+}
+
+func (i *faultInjectorImpl) Inject(f fault.Fault) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	// on demand faults start right away, without a
+	// duration they last until they are cleared
+	f.Start = i.getElapsed()
+	f.Repeat = 0
+	if f.Duration <= 0 {
+		f.Duration = math.MaxInt64 - f.Start
+	}
+	i.logger.Info(fmt.Sprintf("injecting fault %v for %d ms", f.FaultType, f.Duration))
+	i.injected = append(i.injected, f)
+}
+
+func (i *faultInjectorImpl) Clear() {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.injected = make([]fault.Fault, 0)
+}
+
+// GetActiveFaults returns the scheduled & on demand faults active right now
+func (i *faultInjectorImpl) GetActiveFaults() []fault.Fault {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.getActiveFaults(i.getElapsed())
+}
+
+// getElapsed returns the simulated time since the engine loop started,
+// before the loop starts the first use of the injector counts as its start
+func (i *faultInjectorImpl) getElapsed() int64 {
+	now := i.clock.Now()
+	if i.origin == nil {
+		i.origin = &now
+	}
+	return now.Sub(*i.origin).Milliseconds()
+}
+
+func (i *faultInjectorImpl) getActiveFaults(t int64) []fault.Fault {
+	active := make([]fault.Fault, 0)
+	for _, f := range i.scheduled {
+		if f.IsActiveAt(t) {
+			active = append(active, f)
+		}
+	}
+
+	// drop on demand faults that already ran their course
+	remaining := i.injected[:0]
+	for _, f := range i.injected {
+		if t >= f.Start+f.Duration {
+			continue
+		}
+		remaining = append(remaining, f)
+		if f.IsActiveAt(t) {
+			active = append(active, f)
+		}
+	}
+	i.injected = remaining
+	return active
+}
+
+func makeValue(v waveformvalue.WaveformPointValue, n float64, b bool) waveformvalue.WaveformPointValue {
+	switch v.(type) {
+	case *waveformvalue.Transition:
+		return &waveformvalue.Transition{Value: b}
+	case *waveformvalue.IntegerValue:
+		return &waveformvalue.IntegerValue{Value: int32(math.Round(n))}
+	}
+	return &waveformvalue.DoubleValue{Value: n}
+}
+
+func makeNumericValue(v waveformvalue.WaveformPointValue, f func(float64) float64) waveformvalue.WaveformPointValue {
+	// numeric faults have no meaning for boolean values,
+	// integer values can not hold NaN or infinity
+	switch t := v.GetValue().(type) {
+	case float64:
+		return &waveformvalue.DoubleValue{Value: f(t)}
+	case int32:
+		if n := f(float64(t)); !math.IsNaN(n) && !math.IsInf(n, 0) {
+			return &waveformvalue.IntegerValue{Value: int32(math.Round(n))}
+		}
+	}
+	return v
+}
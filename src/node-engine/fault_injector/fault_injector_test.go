@@ -0,0 +1,119 @@
+package faultinjector_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	faultinjector "github.com/AndreiLacatos/opc-engine/node-engine/fault_injector"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	"go.uber.org/zap"
+)
+
+// an hour long window, active for the whole test
+const hour = int64(3600000)
+
+func TestApply(t *testing.T) {
+	double := func(v float64) waveformvalue.WaveformPointValue { return &waveformvalue.DoubleValue{Value: v} }
+	integer := func(v int32) waveformvalue.WaveformPointValue { return &waveformvalue.IntegerValue{Value: v} }
+	boolean := func(v bool) waveformvalue.WaveformPointValue { return &waveformvalue.Transition{Value: v} }
+	for _, tc := range []struct {
+		name      string
+		faults    []fault.Fault
+		value     waveformvalue.WaveformPointValue
+		want      any
+		published bool
+	}{
+		{"no faults", nil, double(1), 1.0, true},
+		{"not started yet", []fault.Fault{{FaultType: fault.StuckAt, Start: hour, Duration: hour, Value: 5}}, double(1), 1.0, true},
+		{"stuck double", []fault.Fault{{FaultType: fault.StuckAt, Duration: hour, Value: 5}}, double(1), 5.0, true},
+		{"stuck integer rounded", []fault.Fault{{FaultType: fault.StuckAt, Duration: hour, Value: 4.6}}, integer(1), int32(5), true},
+		{"stuck boolean", []fault.Fault{{FaultType: fault.StuckAt, Duration: hour, Value: 1}}, boolean(false), true, true},
+		{"offset", []fault.Fault{{FaultType: fault.Offset, Duration: hour, Value: -2.5}}, double(1), -1.5, true},
+		{"offset on boolean ignored", []fault.Fault{{FaultType: fault.Offset, Duration: hour, Value: 2}}, boolean(true), true, true},
+		{"stuck then offset", []fault.Fault{{FaultType: fault.StuckAt, Duration: hour, Value: 5}, {FaultType: fault.Offset, Duration: hour, Value: 1}}, double(1), 6.0, true},
+		{"infinity", []fault.Fault{{FaultType: fault.Infinity, Duration: hour, Value: -1}}, double(1), math.Inf(-1), true},
+		{"infinity on integer ignored", []fault.Fault{{FaultType: fault.Infinity, Duration: hour}}, integer(3), int32(3), true},
+		{"dropped updates", []fault.Fault{{FaultType: fault.DroppedUpdates, Duration: hour}}, double(1), 1.0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			i := faultinjector.CreateNew(tc.faults, nil, zap.NewNop())
+
+			v, published := i.Apply(tc.value)
+
+			if published != tc.published {
+				t.Errorf("expected published to be %v, got %v", tc.published, published)
+			}
+			if v.GetValue() != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, v.GetValue())
+			}
+		})
+	}
+}
+
+func TestApply_NotANumber(t *testing.T) {
+	i := faultinjector.CreateNew([]fault.Fault{{FaultType: fault.NotANumber, Duration: hour}}, nil, zap.NewNop())
+
+	v, _ := i.Apply(&waveformvalue.DoubleValue{Value: 1})
+
+	if !math.IsNaN(v.GetValue().(float64)) {
+		t.Errorf("expected NaN, got %v", v.GetValue())
+	}
+}
+
+func TestInject_FlatlineHoldsLastValueUntilCleared(t *testing.T) {
+	i := faultinjector.CreateNew(nil, nil, zap.NewNop())
+	i.Apply(&waveformvalue.DoubleValue{Value: 1})
+	i.Apply(&waveformvalue.DoubleValue{Value: 2})
+
+	i.Inject(fault.Fault{FaultType: fault.Flatline})
+	held, _ := i.Apply(&waveformvalue.DoubleValue{Value: 3})
+	active := i.GetActiveFaults()
+	i.Clear()
+	released, _ := i.Apply(&waveformvalue.DoubleValue{Value: 4})
+
+	if held.GetValue() != 2.0 {
+		t.Errorf("expected the last value before the fault, got %v", held.GetValue())
+	}
+	if len(active) != 1 || active[0].FaultType != fault.Flatline {
+		t.Errorf("expected the injected fault to be active, got %+v", active)
+	}
+	if released.GetValue() != 4.0 || len(i.GetActiveFaults()) != 0 {
+		t.Errorf("expected the fault to be cleared, got %v", released.GetValue())
+	}
+}
+
+func TestRestart_FaultsCountFromTheLoopStart(t *testing.T) {
+	clock := simulationclock.CreateNew()
+	i := faultinjector.CreateNew([]fault.Fault{{FaultType: fault.StuckAt, Start: hour, Duration: hour, Value: 5}}, clock, zap.NewNop())
+	i.Inject(fault.Fault{FaultType: fault.Offset, Duration: 2 * hour, Value: 1})
+
+	before, _ := i.Apply(&waveformvalue.DoubleValue{Value: 1})
+	i.Restart(clock.Now().Add(-time.Duration(hour) * time.Millisecond))
+	after, _ := i.Apply(&waveformvalue.DoubleValue{Value: 1})
+
+	if before.GetValue() != 2.0 {
+		t.Errorf("expected only the injected fault before the restart, got %v", before.GetValue())
+	}
+	if after.GetValue() != 6.0 {
+		t.Errorf("expected the scheduled fault to start an hour after the loop & the injected one to go on, got %v", after.GetValue())
+	}
+}
+
+func TestApply_FaultsFollowTheSimulationSpeed(t *testing.T) {
+	clock := simulationclock.CreateNew()
+	if err := clock.SetSpeed(1000); err != nil {
+		t.Fatal(err)
+	}
+	i := faultinjector.CreateNew([]fault.Fault{{FaultType: fault.StuckAt, Start: 5000, Duration: hour, Value: 5}}, clock, zap.NewNop())
+	i.Restart(clock.Now())
+
+	time.Sleep(10 * time.Millisecond)
+	v, _ := i.Apply(&waveformvalue.DoubleValue{Value: 1})
+
+	if v.GetValue() != 5.0 {
+		t.Errorf("expected the fault to start after 5 simulated seconds, got %v", v.GetValue())
+	}
+}
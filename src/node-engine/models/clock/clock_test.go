@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	tick := time.Date(2024, time.March, 4, 6, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		clock   *SourceClock
+		elapsed time.Duration
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"no source clock", nil, time.Hour, 0, 0},
+		{"offset", &SourceClock{Offset: -2000}, time.Hour, -2 * time.Second, -2 * time.Second},
+		{"drift", &SourceClock{Drift: 1000}, 30 * time.Minute, 500 * time.Millisecond, 500 * time.Millisecond},
+		{"offset & drift", &SourceClock{Offset: 100, Drift: -60}, 2 * time.Hour, -20 * time.Millisecond, -20 * time.Millisecond},
+		{"jitter", &SourceClock{Offset: 1000, Jitter: 50}, 0, 950 * time.Millisecond, 1050 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(42))
+			for range 100 {
+				d := tc.clock.Apply(tick, tc.elapsed, r).Sub(tick)
+				if d < tc.min || d > tc.max {
+					t.Fatalf("expected a deviation between %s & %s, got %s", tc.min, tc.max, d)
+				}
+			}
+		})
+	}
+}
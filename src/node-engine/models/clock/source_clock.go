@@ -0,0 +1,27 @@
+package clock
+
+import (
+	"math/rand"
+	"time"
+)
+
+// SourceClock simulates a device with an inaccurate clock; Offset is a
+// constant deviation, Drift is the deviation accumulated per hour of
+// simulation & Jitter the maximum random deviation, all in milliseconds
+type SourceClock struct {
+	Offset int64
+	Drift  float64
+	Jitter int64
+}
+
+func (c *SourceClock) Apply(t time.Time, elapsed time.Duration, r *rand.Rand) time.Time {
+	if c == nil {
+		return t
+	}
+
+	deviation := float64(c.Offset) + c.Drift*elapsed.Hours()
+	if c.Jitter > 0 {
+		deviation += float64(r.Int63n(2*c.Jitter+1) - c.Jitter)
+	}
+	return t.Add(time.Duration(deviation * float64(time.Millisecond)))
+}
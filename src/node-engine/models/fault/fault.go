@@ -0,0 +1,44 @@
+package fault
+
+type FaultType int
+
+const (
+	StuckAt FaultType = iota
+	DroppedUpdates
+	Spike
+	NotANumber
+	Infinity
+	Offset
+	Flatline
+)
+
+// Fault alters the values of a node during its active window; Start is
+// relative to the start of the node's engine loop, Repeat is the period
+// after which the window reoccurs, zero meaning it only happens once
+type Fault struct {
+	FaultType FaultType
+	Start     int64
+	Duration  int64
+	Repeat    int64
+	Value     float64
+}
+
+func (f *Fault) IsActiveAt(t int64) bool {
+	return f.GetElapsedAt(t) >= 0
+}
+
+// GetElapsedAt returns the time elapsed since the start of the window active
+// at t, or -1 if the fault is not active at t
+func (f *Fault) GetElapsedAt(t int64) int64 {
+	if t < f.Start {
+		return -1
+	}
+	elapsed := t - f.Start
+	if f.Repeat > 0 {
+		elapsed %= f.Repeat
+	}
+	if elapsed >= f.Duration {
+		return -1
+	}
+	return elapsed
+}
@@ -0,0 +1,29 @@
+package fault
+
+import "testing"
+
+func TestGetElapsedAt(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		fault Fault
+		at    int64
+		want  int64
+	}{
+		{"before the start", Fault{Start: 300, Duration: 400}, 299, -1},
+		{"at the start", Fault{Start: 300, Duration: 400}, 300, 0},
+		{"within the window", Fault{Start: 300, Duration: 400}, 650, 350},
+		{"at the end", Fault{Start: 300, Duration: 400}, 700, -1},
+		{"one-off window passed", Fault{Start: 300, Duration: 400}, 1300, -1},
+		{"repeated window", Fault{Start: 300, Duration: 400, Repeat: 1000}, 1350, 50},
+		{"between repeated windows", Fault{Start: 300, Duration: 400, Repeat: 1000}, 1200, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if e := tc.fault.GetElapsedAt(tc.at); e != tc.want {
+				t.Errorf("expected %d, got %d", tc.want, e)
+			}
+			if a := tc.fault.IsActiveAt(tc.at); a != (tc.want >= 0) {
+				t.Errorf("expected active to be %v, got %v", tc.want >= 0, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package opcnode
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+// OpcTemplateNode is a folder of value nodes generated from a template,
+// the ids of the value nodes are derived from the id of the template node
+type OpcTemplateNode struct {
+	Id            uuid.UUID
+	Label         string
+	TickFrequency int32
+	Template      template.Template
+	Children      []OpcStructureNode
+}
+
+func CreateTemplateNode(id uuid.UUID, label string, tickFrequency int32, t template.Template) *OpcTemplateNode {
+	n := &OpcTemplateNode{
+		Id:            id,
+		Label:         label,
+		TickFrequency: tickFrequency,
+		Template:      t,
+		Children:      make([]OpcStructureNode, 0),
+	}
+	groups := make(map[string]*OpcContainerNode)
+	for _, s := range t.GetSignals() {
+		var m waveform.WaveformMeta = waveform.SimulatedWaveformMeta{
+			ValueType: s.ValueType,
+			Signal:    s.Key,
+			Writable:  s.Writable,
+		}
+		parent := &n.Children
+		if s.Group != "" {
+			g, found := groups[s.Group]
+			if !found {
+				g = &OpcContainerNode{
+					Id:       n.GetSignalId("group:" + s.Group),
+					Label:    s.Group,
+					Children: make([]OpcStructureNode, 0),
+				}
+				groups[s.Group] = g
+				n.Children = append(n.Children, g)
+			}
+			parent = &g.Children
+		}
+		*parent = append(*parent, &OpcValueNode{
+			Id:    n.GetSignalId(s.Key),
+			Label: s.Label,
+			Waveform: waveform.Waveform{
+				Duration:      int64(tickFrequency),
+				TickFrequency: tickFrequency,
+				WaveformType:  waveform.Simulated,
+				Meta:          &m,
+			},
+		})
+	}
+	return n
+}
+
+func (t *OpcTemplateNode) GetId() uuid.UUID {
+	return t.Id
+}
+
+func (t *OpcTemplateNode) GetLabel() string {
+	return t.Label
+}
+
+func (t *OpcTemplateNode) GetSignalId(key string) uuid.UUID {
+	return uuid.NewSHA1(t.Id, []byte(key))
+}
+
+// GetSignalNodes returns the value nodes of the template, keyed by signal
+func (t *OpcTemplateNode) GetSignalNodes() map[string]OpcValueNode {
+	res := make(map[string]OpcValueNode)
+	collectSignalNodes(t.Children, res)
+	return res
+}
+
+func collectSignalNodes(c []OpcStructureNode, res map[string]OpcValueNode) {
+	for _, n := range c {
+		switch t := n.(type) {
+		case *OpcContainerNode:
+			collectSignalNodes(t.Children, res)
+		case *OpcValueNode:
+			if t.Waveform.Meta == nil {
+				continue
+			}
+			if m, ok := (*t.Waveform.Meta).(waveform.SimulatedWaveformMeta); ok {
+				res[m.Signal] = *t
+			}
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package opcnode
 
 import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/clock"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	"github.com/google/uuid"
 )
 
 type OpcValueNode struct {
-	Id       uuid.UUID
-	Label    string
-	Waveform waveform.Waveform
+	Id          uuid.UUID
+	Label       string
+	Waveform    waveform.Waveform
+	Faults      []fault.Fault
+	Quality     *quality.QualityProfile
+	SourceClock *clock.SourceClock
+	Override    *override.OverrideBehavior
 }
 
 func (v *OpcValueNode) GetId() uuid.UUID {
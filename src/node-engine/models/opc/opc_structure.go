@@ -1,7 +1,13 @@
 package opc
 
-import opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+import (
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+)
 
 type OpcStructure struct {
-	Root opcnode.OpcContainerNode
+	Root      opcnode.OpcContainerNode
+	Scenarios []scenario.Scenario
+	Triggers  []trigger.Trigger
 }
@@ -0,0 +1,131 @@
+package opc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/google/uuid"
+)
+
+// NodeChange is a node of the structure along with the id of its
+// parent, the parent of the root node is the nil UUID
+type NodeChange struct {
+	Node   opcnode.OpcStructureNode
+	Parent uuid.UUID
+}
+
+// StructureDiff lists the changes between two structures; added & removed
+// nodes include their subtree, nodes which were moved, relabeled or changed
+// their kind or value type are both removed & added, updated value nodes
+// only differ in their behavior (waveform, faults, quality and so on)
+type StructureDiff struct {
+	Removed []NodeChange
+	Added   []NodeChange
+	Updated []NodeChange
+}
+
+func (d *StructureDiff) IsEmpty() bool {
+	return len(d.Removed) == 0 && len(d.Added) == 0 && len(d.Updated) == 0
+}
+
+func Diff(o, n OpcStructure) StructureDiff {
+	oldNodes := indexNodes(&o.Root, uuid.Nil)
+	newNodes := indexNodes(&n.Root, uuid.Nil)
+	d := StructureDiff{
+		Removed: make([]NodeChange, 0),
+		Added:   make([]NodeChange, 0),
+		Updated: make([]NodeChange, 0),
+	}
+
+	walkNodes(&o.Root, uuid.Nil, func(c NodeChange) bool {
+		if m, found := newNodes[c.Node.GetId()]; !found || isReplaced(c, m) {
+			d.Removed = append(d.Removed, c)
+			return false
+		}
+		return true
+	})
+	walkNodes(&n.Root, uuid.Nil, func(c NodeChange) bool {
+		m, found := oldNodes[c.Node.GetId()]
+		if !found || isReplaced(m, c) {
+			d.Added = append(d.Added, c)
+			return false
+		}
+		if !reflect.DeepEqual(m.Node, c.Node) {
+			if _, ok := c.Node.(*opcnode.OpcValueNode); ok {
+				d.Updated = append(d.Updated, c)
+			}
+		}
+		return true
+	})
+	return d
+}
+
+// walkNodes visits the nodes of the structure in pre-order, the children
+// of a node are skipped if the visit returns false; templates are visited
+// as a whole since their children are generated
+func walkNodes(n opcnode.OpcStructureNode, p uuid.UUID, visit func(NodeChange) bool) {
+	if !visit(NodeChange{Node: n, Parent: p}) {
+		return
+	}
+	if c, ok := n.(*opcnode.OpcContainerNode); ok {
+		for _, child := range c.Children {
+			walkNodes(child, c.Id, visit)
+		}
+	}
+}
+
+func indexNodes(r opcnode.OpcStructureNode, p uuid.UUID) map[uuid.UUID]NodeChange {
+	res := make(map[uuid.UUID]NodeChange)
+	walkNodes(r, p, func(c NodeChange) bool {
+		res[c.Node.GetId()] = c
+		return true
+	})
+	return res
+}
+
+// isReplaced tells whether the node can not be updated in place
+func isReplaced(o, n NodeChange) bool {
+	if o.Parent != n.Parent || o.Node.GetLabel() != n.Node.GetLabel() {
+		return true
+	}
+	switch t := o.Node.(type) {
+	case *opcnode.OpcContainerNode:
+		_, ok := n.Node.(*opcnode.OpcContainerNode)
+		return !ok
+	case *opcnode.OpcTemplateNode:
+		return !reflect.DeepEqual(t, n.Node)
+	case *opcnode.OpcValueNode:
+		m, ok := n.Node.(*opcnode.OpcValueNode)
+		return !ok || t.Waveform.GetValueType() != m.Waveform.GetValueType()
+	default:
+		return true
+	}
+}
+
+// Summary describes the changes by the labels of the affected nodes
+func (d *StructureDiff) Summary() string {
+	if d.IsEmpty() {
+		return "no node changes"
+	}
+	parts := make([]string, 0, 3)
+	for _, c := range []struct {
+		verb    string
+		changes []NodeChange
+	}{
+		{"removed", d.Removed},
+		{"added", d.Added},
+		{"updated", d.Updated},
+	} {
+		if len(c.changes) == 0 {
+			continue
+		}
+		labels := make([]string, len(c.changes))
+		for i, n := range c.changes {
+			labels[i] = n.Node.GetLabel()
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", c.verb, strings.Join(labels, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
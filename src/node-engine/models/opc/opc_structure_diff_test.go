@@ -0,0 +1,89 @@
+package opc_test
+
+import (
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+func TestDiff(t *testing.T) {
+	step := makeWaveform(waveform.NumericValues, waveform.NumericWaveformMeta{Smoothing: waveform.Step})
+	linear := makeWaveform(waveform.NumericValues, waveform.NumericWaveformMeta{Smoothing: waveform.Linear})
+	tankId := uuid.MustParse("4e5f6a7b-8c9d-4e0f-9a2b-3c4d5e6f7a8b")
+	withTank := func(level float64) opc.OpcStructure {
+		s := makeStructure(step)
+		s.Root.Children = append(s.Root.Children, opcnode.CreateTemplateNode(tankId, "Tank", 100, template.TankTemplate{Area: 2, Height: 4, Level: level}))
+		return s
+	}
+	edit := func(edit func(*opc.OpcStructure)) opc.OpcStructure {
+		s := makeStructure(step)
+		edit(&s)
+		return s
+	}
+	folder := func(s *opc.OpcStructure) *opcnode.OpcContainerNode {
+		return s.Root.Children[1].(*opcnode.OpcContainerNode)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		old     opc.OpcStructure
+		new     opc.OpcStructure
+		summary string
+	}{
+		{"unchanged", makeStructure(step), makeStructure(step), "no node changes"},
+		{"waveform changed", makeStructure(step), makeStructure(linear), "updated Target"},
+		{"value type changed", makeStructure(step), makeStructure(makeWaveform(waveform.Transitions, nil)), "removed Target; added Target"},
+		{
+			"value node relabeled",
+			makeStructure(step),
+			edit(func(s *opc.OpcStructure) { folder(s).Children[0].(*opcnode.OpcValueNode).Label = "Renamed" }),
+			"removed Target; added Renamed",
+		},
+		{
+			"value node moved",
+			makeStructure(step),
+			edit(func(s *opc.OpcStructure) {
+				s.Root.Children = append(s.Root.Children, folder(s).Children[0])
+				folder(s).Children = nil
+			}),
+			"removed Target; added Target",
+		},
+		{
+			"container relabeled along with its subtree",
+			makeStructure(step),
+			edit(func(s *opc.OpcStructure) { folder(s).Label = "Renamed" }),
+			"removed Folder; added Renamed",
+		},
+		{
+			"container replaced by a value node",
+			makeStructure(step),
+			edit(func(s *opc.OpcStructure) {
+				s.Root.Children[1] = &opcnode.OpcValueNode{Id: folderId, Label: "Folder", Waveform: step}
+			}),
+			"removed Folder; added Folder",
+		},
+		{
+			"node added",
+			makeStructure(step),
+			edit(func(s *opc.OpcStructure) {
+				s.Root.Children = append(s.Root.Children, &opcnode.OpcValueNode{Id: uuid.New(), Label: "Added", Waveform: step})
+			}),
+			"added Added",
+		},
+		{"node removed", withTank(1), makeStructure(step), "removed Tank"},
+		{"template unchanged", withTank(1), withTank(1), "no node changes"},
+		{"template changed", withTank(1), withTank(2), "removed Tank; added Tank"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := opc.Diff(tc.old, tc.new)
+
+			if s := d.Summary(); s != tc.summary {
+				t.Errorf("expected %q, got %q", tc.summary, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package opc
+
+import (
+	"errors"
+	"fmt"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/google/uuid"
+)
+
+// Validate checks that the structure can be served & simulated,
+// all the problems found are reported at once
+func (s *OpcStructure) Validate() error {
+	errs := make([]error, 0)
+	nodes := make(map[uuid.UUID]opcnode.OpcStructureNode)
+	values := make([]*opcnode.OpcValueNode, 0)
+	var validate func(opcnode.OpcStructureNode)
+	validate = func(n opcnode.OpcStructureNode) {
+		if _, found := nodes[n.GetId()]; found {
+			errs = append(errs, fmt.Errorf("duplicate node id %s", n.GetId()))
+		}
+		nodes[n.GetId()] = n
+		switch t := n.(type) {
+		case *opcnode.OpcContainerNode:
+			for _, c := range t.Children {
+				validate(c)
+			}
+		case *opcnode.OpcTemplateNode:
+			if t.TickFrequency <= 0 {
+				errs = append(errs, fmt.Errorf("template %s has no tick frequency", opcnode.ToDebugString(t)))
+			}
+			for _, c := range t.Children {
+				validate(c)
+			}
+		case *opcnode.OpcValueNode:
+			if err := t.Waveform.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("value node %s: %w", opcnode.ToDebugString(t), err))
+			}
+			values = append(values, t)
+		}
+	}
+	validate(&s.Root)
+
+	isValueNode := func(id uuid.UUID) bool {
+		_, ok := nodes[id].(*opcnode.OpcValueNode)
+		return ok
+	}
+	for _, n := range values {
+		for _, id := range n.Waveform.GetReferencedNodes() {
+			if !isValueNode(id) {
+				errs = append(errs, fmt.Errorf("value node %s refers to %s, which is not a value node", opcnode.ToDebugString(n), id))
+			}
+		}
+	}
+
+	scenarios := make(map[string]bool)
+	for _, sc := range s.Scenarios {
+		scenarios[sc.Name] = true
+		for i, a := range sc.Actions {
+			if err := validateScenarioAction(a, nodes, isValueNode); err != nil {
+				errs = append(errs, fmt.Errorf("action %d of scenario %s: %w", i+1, sc.Name, err))
+			}
+		}
+	}
+	for _, t := range s.Triggers {
+		if !isValueNode(t.Source) {
+			errs = append(errs, fmt.Errorf("trigger source %s is not a value node", t.Source))
+		}
+		for _, a := range t.Actions {
+			if a.ActionType == trigger.StartScenario {
+				if !scenarios[a.Scenario] {
+					errs = append(errs, fmt.Errorf("trigger of %s starts unknown scenario %s", t.Source, a.Scenario))
+				}
+			} else if !isValueNode(a.NodeId) {
+				errs = append(errs, fmt.Errorf("trigger of %s targets %s, which is not a value node", t.Source, a.NodeId))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateScenarioAction(a scenario.Action, nodes map[uuid.UUID]opcnode.OpcStructureNode, isValueNode func(uuid.UUID) bool) error {
+	switch a.ActionType {
+	case scenario.ChangeSpeed:
+		if a.Speed <= 0 {
+			return fmt.Errorf("speed must be positive")
+		}
+	case scenario.EnableNodes, scenario.DisableNodes:
+		if _, found := nodes[a.NodeId]; !found {
+			return fmt.Errorf("node %s not found", a.NodeId)
+		}
+	case scenario.SwitchWaveform:
+		if !isValueNode(a.NodeId) {
+			return fmt.Errorf("%s is not a value node", a.NodeId)
+		}
+		if a.Waveform == nil {
+			return fmt.Errorf("missing waveform")
+		}
+		if err := a.Waveform.Validate(); err != nil {
+			return err
+		}
+		for _, id := range a.Waveform.GetReferencedNodes() {
+			if !isValueNode(id) {
+				return fmt.Errorf("waveform refers to %s, which is not a value node", id)
+			}
+		}
+	default:
+		if !isValueNode(a.NodeId) {
+			return fmt.Errorf("%s is not a value node", a.NodeId)
+		}
+	}
+	return nil
+}
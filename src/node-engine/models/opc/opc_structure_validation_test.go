@@ -0,0 +1,134 @@
+package opc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+var (
+	rootId   = uuid.MustParse("3d4e5f6a-7b8c-4d9e-8f1a-2b3c4d5e6f7a")
+	sourceId = uuid.MustParse("0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d")
+	targetId = uuid.MustParse("1b2c3d4e-5f6a-4b7c-8d9e-0f1a2b3c4d5e")
+	folderId = uuid.MustParse("2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e6f")
+)
+
+func makeWaveform(t waveform.WaveformType, meta waveform.WaveformMeta) waveform.Waveform {
+	w := waveform.Waveform{
+		Duration:      1000,
+		TickFrequency: 100,
+		WaveformType:  t,
+	}
+	if meta != nil {
+		w.Meta = &meta
+	}
+	return w
+}
+
+func makeStructure(target waveform.Waveform) opc.OpcStructure {
+	return opc.OpcStructure{
+		Root: opcnode.OpcContainerNode{
+			Id:    rootId,
+			Label: "Root",
+			Children: []opcnode.OpcStructureNode{
+				&opcnode.OpcValueNode{
+					Id:       sourceId,
+					Label:    "Source",
+					Waveform: makeWaveform(waveform.NumericValues, waveform.NumericWaveformMeta{Smoothing: waveform.Step}),
+				},
+				&opcnode.OpcContainerNode{
+					Id:    folderId,
+					Label: "Folder",
+					Children: []opcnode.OpcStructureNode{
+						&opcnode.OpcValueNode{
+							Id:       targetId,
+							Label:    "Target",
+							Waveform: target,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	step := makeWaveform(waveform.NumericValues, waveform.NumericWaveformMeta{Smoothing: waveform.Step})
+	noTicks := step
+	noTicks.TickFrequency = 0
+	noDuration := step
+	noDuration.Duration = 0
+	markovStates := []waveform.MarkovState{{}, {}}
+	withTriggers := func(tr ...trigger.Trigger) opc.OpcStructure {
+		s := makeStructure(step)
+		s.Triggers = tr
+		return s
+	}
+	withScenario := func(a ...scenario.Action) opc.OpcStructure {
+		s := makeStructure(step)
+		s.Scenarios = []scenario.Scenario{{Name: "Startup", Actions: a}}
+		return s
+	}
+	duplicate := makeStructure(step)
+	duplicate.Root.Children = append(duplicate.Root.Children, &opcnode.OpcContainerNode{Id: folderId, Label: "Copy"})
+
+	for _, tc := range []struct {
+		name      string
+		structure opc.OpcStructure
+		err       string
+	}{
+		{"valid structure", makeStructure(step), ""},
+		{"transitions without meta", makeStructure(makeWaveform(waveform.Transitions, nil)), ""},
+		{"duplicate id", duplicate, "duplicate node id"},
+		{"no tick frequency", makeStructure(noTicks), "no tick frequency"},
+		{"no duration", makeStructure(noDuration), "no waveform duration"},
+		{"numeric without meta", makeStructure(makeWaveform(waveform.NumericValues, nil)), "smoothing"},
+		{"schedule without meta", makeStructure(makeWaveform(waveform.Schedule, nil)), "schedule"},
+		{"filter without meta", makeStructure(makeWaveform(waveform.Filter, nil)), "filter definition"},
+		{"mismatched meta", makeStructure(makeWaveform(waveform.Counter, waveform.NumericWaveformMeta{})), "counter definition"},
+		{"markov without states", makeStructure(makeWaveform(waveform.Markov, waveform.MarkovWaveformMeta{})), "without states"},
+		{
+			"markov with missing weights",
+			makeStructure(makeWaveform(waveform.Markov, waveform.MarkovWaveformMeta{States: markovStates, Transitions: [][]float64{{1, 1}}})),
+			"rows of transition weights",
+		},
+		{
+			"markov with negative weight",
+			makeStructure(makeWaveform(waveform.Markov, waveform.MarkovWaveformMeta{States: markovStates, Transitions: [][]float64{{1, -1}, {1, 1}}})),
+			"negative transition weight",
+		},
+		{"tracking an existing value node", makeStructure(makeWaveform(waveform.Tracking, waveform.TrackingWaveformMeta{Source: sourceId})), ""},
+		{"tracking an unknown node", makeStructure(makeWaveform(waveform.Tracking, waveform.TrackingWaveformMeta{Source: uuid.New()})), "not a value node"},
+		{"filtering a container", makeStructure(makeWaveform(waveform.Filter, waveform.FilterWaveformMeta{Source: folderId})), "not a value node"},
+		{"counter reset by an unknown node", makeStructure(makeWaveform(waveform.Counter, waveform.CounterWaveformMeta{Source: sourceId, Reset: &folderId})), "not a value node"},
+		{"valid trigger", withTriggers(trigger.Trigger{Source: sourceId, Actions: []trigger.Action{{ActionType: trigger.StopWaveform, NodeId: targetId}}}), ""},
+		{"trigger on unknown source", withTriggers(trigger.Trigger{Source: uuid.New()}), "trigger source"},
+		{"trigger on unknown target", withTriggers(trigger.Trigger{Source: sourceId, Actions: []trigger.Action{{ActionType: trigger.SetValue, NodeId: folderId}}}), "not a value node"},
+		{"trigger starting unknown scenario", withTriggers(trigger.Trigger{Source: sourceId, Actions: []trigger.Action{{ActionType: trigger.StartScenario, Scenario: "Nope"}}}), "unknown scenario"},
+		{"scenario disabling a container", withScenario(scenario.Action{ActionType: scenario.DisableNodes, NodeId: folderId}), ""},
+		{"scenario disabling an unknown node", withScenario(scenario.Action{ActionType: scenario.DisableNodes, NodeId: uuid.New()}), "not found"},
+		{"scenario faulting a container", withScenario(scenario.Action{ActionType: scenario.InjectFault, NodeId: folderId}), "not a value node"},
+		{"scenario stopping the clock", withScenario(scenario.Action{ActionType: scenario.ChangeSpeed}), "speed must be positive"},
+		{"scenario switching to no waveform", withScenario(scenario.Action{ActionType: scenario.SwitchWaveform, NodeId: targetId}), "missing waveform"},
+		{"scenario switching to waveform without ticks", withScenario(scenario.Action{ActionType: scenario.SwitchWaveform, NodeId: targetId, Waveform: &noTicks}), "no tick frequency"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.structure.Validate()
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("expected the structure to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected an error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package override
+
+import (
+	"time"
+
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+)
+
+type OverrideMode int
+
+const (
+	// Hold keeps the written value for a given duration
+	Hold OverrideMode = iota
+	// UntilReset keeps the written value until the override is cleared
+	UntilReset
+	// Forever keeps the written value, the override can not be cleared
+	Forever
+)
+
+// OverrideBehavior makes a value node writable, a client write suspends the
+// simulation of the node; Duration is only used in Hold mode, in milliseconds
+type OverrideBehavior struct {
+	Mode     OverrideMode
+	Duration int64
+}
+
+type OverrideState struct {
+	Mode  OverrideMode
+	Value waveformvalue.WaveformPointValue
+	Since time.Time
+	Until *time.Time
+}
@@ -0,0 +1,71 @@
+package quality
+
+// StatusCode holds an OPC UA status code
+type StatusCode uint32
+
+const (
+	Good                                    StatusCode = 0x00000000
+	GoodLocalOverride                       StatusCode = 0x00960000
+	Uncertain                               StatusCode = 0x40000000
+	UncertainNoCommunicationLastUsableValue StatusCode = 0x408F0000
+	UncertainLastUsableValue                StatusCode = 0x40900000
+	UncertainSubstituteValue                StatusCode = 0x40910000
+	UncertainInitialValue                   StatusCode = 0x40920000
+	UncertainSensorNotAccurate              StatusCode = 0x40930000
+	UncertainEngineeringUnitsExceeded       StatusCode = 0x40940000
+	UncertainSubNormal                      StatusCode = 0x40950000
+	Bad                                     StatusCode = 0x80000000
+	BadCommunicationError                   StatusCode = 0x80050000
+	BadTimeout                              StatusCode = 0x800A0000
+	BadNoCommunication                      StatusCode = 0x80310000
+	BadWaitingForInitialData                StatusCode = 0x80320000
+	BadOutOfRange                           StatusCode = 0x803C0000
+	BadConfigurationError                   StatusCode = 0x80890000
+	BadNotConnected                         StatusCode = 0x808A0000
+	BadDeviceFailure                        StatusCode = 0x808B0000
+	BadSensorFailure                        StatusCode = 0x808C0000
+	BadOutOfService                         StatusCode = 0x808D0000
+)
+
+var StatusCodeNames = map[string]StatusCode{
+	"Good":              Good,
+	"GoodLocalOverride": GoodLocalOverride,
+	"Uncertain":         Uncertain,
+	"UncertainNoCommunicationLastUsableValue": UncertainNoCommunicationLastUsableValue,
+	"UncertainLastUsableValue":                UncertainLastUsableValue,
+	"UncertainSubstituteValue":                UncertainSubstituteValue,
+	"UncertainInitialValue":                   UncertainInitialValue,
+	"UncertainSensorNotAccurate":              UncertainSensorNotAccurate,
+	"UncertainEngineeringUnitsExceeded":       UncertainEngineeringUnitsExceeded,
+	"UncertainSubNormal":                      UncertainSubNormal,
+	"Bad":                                     Bad,
+	"BadCommunicationError":                   BadCommunicationError,
+	"BadTimeout":                              BadTimeout,
+	"BadNoCommunication":                      BadNoCommunication,
+	"BadWaitingForInitialData":                BadWaitingForInitialData,
+	"BadOutOfRange":                           BadOutOfRange,
+	"BadConfigurationError":                   BadConfigurationError,
+	"BadNotConnected":                         BadNotConnected,
+	"BadDeviceFailure":                        BadDeviceFailure,
+	"BadSensorFailure":                        BadSensorFailure,
+	"BadOutOfService":                         BadOutOfService,
+}
+
+// QualityTransition switches the status of a node at a given tick of its waveform
+type QualityTransition struct {
+	Tick   int64
+	Status StatusCode
+}
+
+// QualityDegradation randomly switches the status of a node, on every tick
+// with the given probability, for the given duration (in milliseconds)
+type QualityDegradation struct {
+	Probability float64
+	Duration    int64
+	Status      StatusCode
+}
+
+type QualityProfile struct {
+	Timeline    []QualityTransition
+	Degradation *QualityDegradation
+}
@@ -0,0 +1,36 @@
+package scenario
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+type ActionType int
+
+const (
+	SwitchWaveform ActionType = iota
+	InjectFault
+	ClearFaults
+	ChangeSpeed
+	EnableNodes
+	DisableNodes
+)
+
+// Action is applied once the simulated time elapsed since the start of
+// the scenario reaches At; NodeId, Waveform, Fault & Speed are used
+// depending on the action type
+type Action struct {
+	At         int64
+	ActionType ActionType
+	NodeId     uuid.UUID
+	Waveform   *waveform.Waveform
+	Fault      *fault.Fault
+	Speed      float64
+}
+
+// Scenario is a named timeline of actions, ordered by their time
+type Scenario struct {
+	Name    string
+	Actions []Action
+}
@@ -0,0 +1,50 @@
+package template
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+type ControlMode int32
+
+const (
+	Manual ControlMode = iota
+	Auto
+)
+
+const (
+	SetpointSignal     = "setpoint"
+	ProcessValueSignal = "processValue"
+	OutputSignal       = "output"
+	ModeSignal         = "mode"
+)
+
+// ControlLoopTemplate is a first order plus dead time process controlled
+// by a PID controller; Setpoint, Mode & Output are the initial values of
+// the writable signals, TimeConstant & DeadTime are in milliseconds. The
+// value of the optional Disturbance node is added to the process input,
+// Noise is the standard deviation of the measurement noise
+type ControlLoopTemplate struct {
+	Setpoint     float64
+	Mode         ControlMode
+	Output       float64
+	Kp           float64
+	Ki           float64
+	Kd           float64
+	OutputMin    float64
+	OutputMax    float64
+	ProcessGain  float64
+	TimeConstant int64
+	DeadTime     int64
+	Disturbance  *uuid.UUID
+	Noise        float64
+}
+
+func (t ControlLoopTemplate) GetSignals() []Signal {
+	return []Signal{
+		{Key: SetpointSignal, Label: "Setpoint", ValueType: waveform.DoubleValue, Writable: true},
+		{Key: ProcessValueSignal, Label: "ProcessValue", ValueType: waveform.DoubleValue},
+		{Key: OutputSignal, Label: "Output", ValueType: waveform.DoubleValue, Writable: true},
+		{Key: ModeSignal, Label: "Mode", ValueType: waveform.IntegerValue, Writable: true},
+	}
+}
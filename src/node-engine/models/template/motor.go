@@ -0,0 +1,47 @@
+package template
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+const (
+	CommandSignal     = "command"
+	RunningSignal     = "running"
+	SpeedSignal       = "speed"
+	CurrentSignal     = "current"
+	TemperatureSignal = "temperature"
+)
+
+// MotorTemplate is a drive started & stopped by a command; durations are
+// in milliseconds, speeds in rpm, currents in amperes & temperatures in
+// degrees Celsius. StartingCurrent is the inrush current as a multiple of
+// RatedCurrent, NoLoadCurrent the current at no load as a fraction of
+// it. Load is the fraction of the rated load driven, read from the
+// optional LoadSource node when given. TemperatureRise is the winding
+// temperature above ambient at rated current
+type MotorTemplate struct {
+	Running             bool
+	RatedSpeed          float64
+	AccelerationTime    int64
+	DecelerationTime    int64
+	FeedbackDelay       int64
+	RatedCurrent        float64
+	StartingCurrent     float64
+	NoLoadCurrent       float64
+	Load                float64
+	LoadSource          *uuid.UUID
+	AmbientTemperature  float64
+	TemperatureRise     float64
+	ThermalTimeConstant int64
+}
+
+func (t MotorTemplate) GetSignals() []Signal {
+	return []Signal{
+		{Key: CommandSignal, Label: "Command", ValueType: waveform.BooleanValue, Writable: true},
+		{Key: RunningSignal, Label: "Running", ValueType: waveform.BooleanValue},
+		{Key: SpeedSignal, Label: "Speed", ValueType: waveform.DoubleValue},
+		{Key: CurrentSignal, Label: "Current", ValueType: waveform.DoubleValue},
+		{Key: TemperatureSignal, Label: "Temperature", ValueType: waveform.DoubleValue},
+	}
+}
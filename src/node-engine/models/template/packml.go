@@ -0,0 +1,78 @@
+package template
+
+import "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+
+// PackMLState is a state of the PackML state model, numbered as in PackML
+type PackMLState int32
+
+const (
+	Undefined PackMLState = iota
+	Clearing
+	Stopped
+	Starting
+	Idle
+	Suspended
+	Execute
+	Stopping
+	Aborting
+	Aborted
+	Holding
+	Held
+	Unholding
+	Suspending
+	Unsuspending
+	Resetting
+	Completing
+	Complete
+)
+
+// PackMLCommand is a control command of the PackML state model, numbered as in PackML
+type PackMLCommand int32
+
+const (
+	NoCommand PackMLCommand = iota
+	ResetCommand
+	StartCommand
+	StopCommand
+	HoldCommand
+	UnholdCommand
+	SuspendCommand
+	UnsuspendCommand
+	AbortCommand
+	ClearCommand
+)
+
+const (
+	StateSignal = "state"
+	FaultSignal = "fault"
+)
+
+// PackMLTemplate is a machine following the PackML state model; Durations
+// holds how long (in milliseconds) each acting state lasts, ExecuteDuration
+// how long a production run lasts until it completes, 0 meaning it never
+// does. FaultRate is the mean number of random faults per hour of
+// execution, a fault aborts the machine
+type PackMLTemplate struct {
+	InitialState    PackMLState
+	Durations       map[PackMLState]int64
+	ExecuteDuration int64
+	FaultRate       float64
+}
+
+func (t PackMLTemplate) GetSignals() []Signal {
+	return []Signal{
+		{Key: StateSignal, Label: "State", ValueType: waveform.IntegerValue},
+		{Key: CommandSignal, Label: "Command", ValueType: waveform.IntegerValue, Writable: true},
+		{Key: FaultSignal, Label: "Fault", ValueType: waveform.BooleanValue},
+	}
+}
+
+// IsActing tells whether the machine leaves the state on its own once it is done
+func (s PackMLState) IsActing() bool {
+	switch s {
+	case Clearing, Starting, Stopping, Aborting, Holding, Unholding,
+		Suspending, Unsuspending, Resetting, Completing:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package template
+
+import "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+
+const (
+	GoodCountSignal    = "goodCount"
+	ScrapCountSignal   = "scrapCount"
+	BlockedSignal      = "blocked"
+	StarvedSignal      = "starved"
+	DownSignal         = "down"
+	BufferSignal       = "buffer"
+	AvailabilitySignal = "availability"
+	PerformanceSignal  = "performance"
+	QualitySignal      = "quality"
+	OeeSignal          = "oee"
+)
+
+// Station is a station of a production line; CycleTime is the ideal
+// cycle time & Variance its standard deviation, MTBF & MTTR the mean
+// time between failures & to repair, all in milliseconds. ScrapRate is
+// the fraction of parts scrapped, BufferCapacity the number of parts
+// the buffer after the station holds
+type Station struct {
+	Name           string
+	CycleTime      int64
+	Variance       int64
+	ScrapRate      float64
+	MTBF           int64
+	MTTR           int64
+	BufferCapacity int32
+}
+
+// ProductionLineTemplate is a line of stations passing parts through
+// buffers, the first station is never starved & the last never blocked
+type ProductionLineTemplate struct {
+	Stations []Station
+}
+
+func (t ProductionLineTemplate) GetSignals() []Signal {
+	res := make([]Signal, 0)
+	for i, s := range t.Stations {
+		res = append(res,
+			Signal{Key: GetStationSignal(s, GoodCountSignal), Label: "GoodCount", ValueType: waveform.IntegerValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, ScrapCountSignal), Label: "ScrapCount", ValueType: waveform.IntegerValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, BlockedSignal), Label: "Blocked", ValueType: waveform.BooleanValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, StarvedSignal), Label: "Starved", ValueType: waveform.BooleanValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, DownSignal), Label: "Down", ValueType: waveform.BooleanValue, Group: s.Name},
+		)
+		if i < len(t.Stations)-1 {
+			res = append(res,
+				Signal{Key: GetStationSignal(s, BufferSignal), Label: "Buffer", ValueType: waveform.IntegerValue, Group: s.Name})
+		}
+		res = append(res,
+			Signal{Key: GetStationSignal(s, AvailabilitySignal), Label: "Availability", ValueType: waveform.DoubleValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, PerformanceSignal), Label: "Performance", ValueType: waveform.DoubleValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, QualitySignal), Label: "Quality", ValueType: waveform.DoubleValue, Group: s.Name},
+			Signal{Key: GetStationSignal(s, OeeSignal), Label: "OEE", ValueType: waveform.DoubleValue, Group: s.Name},
+		)
+	}
+	return res
+}
+
+func GetStationSignal(s Station, signal string) string {
+	return s.Name + "." + signal
+}
@@ -0,0 +1,47 @@
+package template
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"github.com/google/uuid"
+)
+
+const (
+	LevelSignal      = "level"
+	InflowSignal     = "inflow"
+	OutflowSignal    = "outflow"
+	InletValveSignal = "inletValve"
+	OutletPumpSignal = "outletPump"
+	HighSwitchSignal = "highSwitch"
+	LowSwitchSignal  = "lowSwitch"
+)
+
+// TankTemplate is a tank filled through an inlet valve & emptied by an
+// outlet pump; levels are in meters, Area in square meters & flows in
+// cubic meters per hour. The flows are read from the optional Inflow
+// & Outflow nodes, without them ValveFlow & PumpFlow are used. ValveOpen
+// & PumpRunning are the initial commands of the valve & pump
+type TankTemplate struct {
+	Area        float64
+	Height      float64
+	Level       float64
+	Inflow      *uuid.UUID
+	Outflow     *uuid.UUID
+	ValveFlow   float64
+	PumpFlow    float64
+	ValveOpen   bool
+	PumpRunning bool
+	HighLevel   float64
+	LowLevel    float64
+}
+
+func (t TankTemplate) GetSignals() []Signal {
+	return []Signal{
+		{Key: LevelSignal, Label: "Level", ValueType: waveform.DoubleValue},
+		{Key: InflowSignal, Label: "Inflow", ValueType: waveform.DoubleValue},
+		{Key: OutflowSignal, Label: "Outflow", ValueType: waveform.DoubleValue},
+		{Key: InletValveSignal, Label: "InletValve", ValueType: waveform.BooleanValue, Writable: true},
+		{Key: OutletPumpSignal, Label: "OutletPump", ValueType: waveform.BooleanValue, Writable: true},
+		{Key: HighSwitchSignal, Label: "HighSwitch", ValueType: waveform.BooleanValue},
+		{Key: LowSwitchSignal, Label: "LowSwitch", ValueType: waveform.BooleanValue},
+	}
+}
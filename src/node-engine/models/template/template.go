@@ -0,0 +1,19 @@
+package template
+
+import "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+
+// Signal is a value published by a template, every signal is exposed
+// as a value node of the template, grouped signals in a subfolder;
+// writable signals are inputs of the simulation set by clients
+type Signal struct {
+	Key       string
+	Label     string
+	ValueType waveform.ValueType
+	Group     string
+	Writable  bool
+}
+
+// Template is a composite model that is simulated as a whole
+type Template interface {
+	GetSignals() []Signal
+}
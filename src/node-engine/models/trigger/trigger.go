@@ -0,0 +1,57 @@
+package trigger
+
+import "github.com/google/uuid"
+
+type ConditionType int
+
+const (
+	RisesAbove ConditionType = iota
+	FallsBelow
+	BecomesTrue
+	BecomesFalse
+)
+
+type ActionType int
+
+const (
+	StartWaveform ActionType = iota
+	StopWaveform
+	RestartWaveform
+	SetValue
+	StartScenario
+)
+
+// Action is applied on the node NodeId, Value is used by
+// SetValue & Scenario by StartScenario
+type Action struct {
+	ActionType ActionType
+	NodeId     uuid.UUID
+	Value      float64
+	Scenario   string
+}
+
+// Trigger applies its actions whenever the values emitted
+// for the source node meet the condition
+type Trigger struct {
+	Source    uuid.UUID
+	Condition ConditionType
+	Threshold float64
+	Actions   []Action
+}
+
+// IsMetBy tells whether the change of the source value from previous
+// to current meets the condition, booleans are mapped to 0 & 1
+func (t *Trigger) IsMetBy(previous, current float64) bool {
+	switch t.Condition {
+	case RisesAbove:
+		return previous <= t.Threshold && current > t.Threshold
+	case FallsBelow:
+		return previous >= t.Threshold && current < t.Threshold
+	case BecomesTrue:
+		return previous == 0 && current != 0
+	case BecomesFalse:
+		return previous != 0 && current == 0
+	default:
+		return false
+	}
+}
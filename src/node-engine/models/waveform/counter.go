@@ -0,0 +1,22 @@
+package waveform
+
+import "github.com/google/uuid"
+
+type EdgeType int
+
+const (
+	RisingEdge EdgeType = iota
+	FallingEdge
+	BothEdges
+)
+
+// CounterWaveformMeta counts the edges of a boolean node; once the count
+// reaches ResetAt it restarts from 0, with Rollover the excess is carried
+// over. While the optional Reset node is true the count stays 0
+type CounterWaveformMeta struct {
+	Source   uuid.UUID
+	Edge     EdgeType
+	ResetAt  int32
+	Rollover bool
+	Reset    *uuid.UUID
+}
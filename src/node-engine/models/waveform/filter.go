@@ -0,0 +1,24 @@
+package waveform
+
+import "github.com/google/uuid"
+
+type FilterType int
+
+const (
+	FirstOrderLagFilter FilterType = iota
+	DeadTimeFilter
+	MovingAverageFilter
+	ExponentialSmoothingFilter
+)
+
+// FilterWaveformMeta applies a signal processing block to the value of
+// another node; TimeConstant, Delay & Window are in milliseconds, Alpha is
+// the weight of the newest sample in exponential smoothing
+type FilterWaveformMeta struct {
+	Source       uuid.UUID
+	Filter       FilterType
+	TimeConstant int64
+	Delay        int64
+	Window       int64
+	Alpha        float64
+}
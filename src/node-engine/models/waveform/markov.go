@@ -0,0 +1,41 @@
+package waveform
+
+import waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+
+type DwellDistribution int
+
+const (
+	FixedDwell DwellDistribution = iota
+	UniformDwell
+	ExponentialDwell
+	NormalDwell
+)
+
+// DwellTime is the distribution of the time (in milliseconds) spent
+// in a state; fixed & exponential dwell times only use Mean, uniform
+// ones Min & Max, normal ones Mean & StdDev
+type DwellTime struct {
+	Distribution DwellDistribution
+	Mean         int64
+	Min          int64
+	Max          int64
+	StdDev       int64
+}
+
+type MarkovState struct {
+	Name  string
+	Value waveformvalue.WaveformPointValue
+	Dwell DwellTime
+}
+
+// MarkovWaveformMeta describes a node that moves between discrete states;
+// Transitions[i][j] is the weight of moving from state i to state j once
+// the dwell time of state i elapsed. Runs with the same Seed produce the
+// same sequence of states, without it every run differs
+type MarkovWaveformMeta struct {
+	ValueType   ValueType
+	States      []MarkovState
+	Transitions [][]float64
+	Initial     int
+	Seed        *int64
+}
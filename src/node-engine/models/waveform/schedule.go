@@ -0,0 +1,29 @@
+package waveform
+
+import (
+	"time"
+
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+)
+
+// ScheduleEntry switches the value of a schedule at a given time of day
+type ScheduleEntry struct {
+	TimeOfDay time.Duration
+	Value     waveformvalue.WaveformPointValue
+}
+
+// ScheduleHoliday replaces the regular weekday entries on a given date
+type ScheduleHoliday struct {
+	Year    int
+	Month   time.Month
+	Day     int
+	Entries []ScheduleEntry
+}
+
+type ScheduleWaveformMeta struct {
+	Location  *time.Location
+	ValueType ValueType
+	Default   waveformvalue.WaveformPointValue
+	Days      map[time.Weekday][]ScheduleEntry
+	Holidays  []ScheduleHoliday
+}
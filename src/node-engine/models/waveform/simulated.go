@@ -0,0 +1,11 @@
+package waveform
+
+// SimulatedWaveformMeta describes a value that is not computed from a
+// waveform but published by the simulation of a template, Signal is
+// the key of the value within the template, the simulation takes the
+// values written by clients into account for writable signals
+type SimulatedWaveformMeta struct {
+	ValueType ValueType
+	Signal    string
+	Writable  bool
+}
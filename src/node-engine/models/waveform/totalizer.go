@@ -0,0 +1,15 @@
+package waveform
+
+import "github.com/google/uuid"
+
+// TotalizerWaveformMeta integrates the value of another node over time,
+// the value per second is multiplied by Scale; once the total reaches
+// ResetAt it restarts from 0, with Rollover the excess is carried over.
+// While the optional Reset node is true the total stays 0
+type TotalizerWaveformMeta struct {
+	Source   uuid.UUID
+	Scale    float64
+	ResetAt  float64
+	Rollover bool
+	Reset    *uuid.UUID
+}
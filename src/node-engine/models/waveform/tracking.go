@@ -0,0 +1,24 @@
+package waveform
+
+import "github.com/google/uuid"
+
+type TrackingMode int
+
+const (
+	Ramp TrackingMode = iota
+	FirstOrderLag
+	Overshoot
+)
+
+// TrackingWaveformMeta makes a node follow the value of another node; Rate
+// is the ramp rate in units per second, TimeConstant (in milliseconds) sets
+// how fast the lag & overshoot modes respond & Damping (below 1) how much
+// the overshoot mode overshoots
+type TrackingWaveformMeta struct {
+	Source       uuid.UUID
+	Mode         TrackingMode
+	Rate         float64
+	TimeConstant int64
+	Damping      float64
+	Initial      float64
+}
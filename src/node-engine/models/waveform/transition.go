@@ -0,0 +1,21 @@
+package waveform
+
+type TransitionMode int
+
+const (
+	ScheduledTransitions TransitionMode = iota
+	PoissonPulses
+)
+
+// TransitionWaveformMeta selects how a boolean waveform transitions; in
+// PoissonPulses mode pulses of PulseWidth milliseconds are emitted at
+// random times, Rate times per minute on average. Burstiness is the chance
+// a pulse is followed right away by another one. Runs with the same Seed
+// produce the same pulses, without it every run differs
+type TransitionWaveformMeta struct {
+	Mode       TransitionMode
+	Rate       float64
+	PulseWidth int64
+	Burstiness float64
+	Seed       *int64
+}
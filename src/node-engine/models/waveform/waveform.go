@@ -1,6 +1,10 @@
 package waveform
 
-import waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+import (
+	"time"
+
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+)
 
 type WaveformValue struct {
 	Tick  int64
@@ -12,6 +16,22 @@ type WaveformType int
 const (
 	Transitions WaveformType = iota
 	NumericValues
+	Schedule
+	Tracking
+	Filter
+	Totalizer
+	Counter
+	Simulated
+	Markov
+)
+
+// ValueType is the data type of the values produced by a waveform
+type ValueType int
+
+const (
+	BooleanValue ValueType = iota
+	DoubleValue
+	IntegerValue
 )
 
 type WaveformMeta interface {
@@ -29,10 +49,61 @@ type NumericWaveformMeta struct {
 	Smoothing SmoothingStrategy
 }
 
+// WaveformAlignment pins tick 0 of the waveform to a wall-clock anchor,
+// the anchor's location is used to interpret wall-clock time
+type WaveformAlignment struct {
+	Anchor time.Time
+}
+
 type Waveform struct {
 	Duration         int64
 	TickFrequency    int32
 	WaveformType     WaveformType
 	TransitionPoints []WaveformValue
 	Meta             *WaveformMeta
+	Alignment        *WaveformAlignment
+}
+
+func (w *Waveform) GetValueType() ValueType {
+	switch w.WaveformType {
+	case Transitions:
+		return BooleanValue
+	case Schedule:
+		if w.Meta != nil {
+			if m, ok := (*w.Meta).(ScheduleWaveformMeta); ok {
+				return m.ValueType
+			}
+		}
+	case Counter:
+		return IntegerValue
+	case Simulated:
+		if w.Meta != nil {
+			if m, ok := (*w.Meta).(SimulatedWaveformMeta); ok {
+				return m.ValueType
+			}
+		}
+	case Markov:
+		if w.Meta != nil {
+			if m, ok := (*w.Meta).(MarkovWaveformMeta); ok {
+				return m.ValueType
+			}
+		}
+	}
+	return DoubleValue
+}
+
+// ConsumesWrites tells whether the values computed for the waveform take the
+// values written by clients into account, other nodes only publish them
+func (w *Waveform) ConsumesWrites() bool {
+	switch w.WaveformType {
+	case Totalizer, Counter:
+		return true
+	case Simulated:
+		if w.Meta != nil {
+			if m, ok := (*w.Meta).(SimulatedWaveformMeta); ok {
+				return m.Writable
+			}
+		}
+	}
+	return false
 }
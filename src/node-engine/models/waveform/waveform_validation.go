@@ -0,0 +1,115 @@
+package waveform
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Validate checks that the waveform can be simulated on its own,
+// the nodes it refers to are resolved by the structure
+func (w *Waveform) Validate() error {
+	if w.WaveformType == Simulated {
+		// published by the loop of their template
+		return nil
+	}
+	if w.TickFrequency <= 0 {
+		return fmt.Errorf("no tick frequency")
+	}
+	if w.Duration <= 0 {
+		return fmt.Errorf("no waveform duration")
+	}
+
+	var meta WaveformMeta
+	if w.Meta != nil {
+		meta = *w.Meta
+	}
+	switch w.WaveformType {
+	case Transitions:
+		if _, ok := meta.(TransitionWaveformMeta); meta != nil && !ok {
+			return fmt.Errorf("invalid transition meta")
+		}
+	case NumericValues:
+		if _, ok := meta.(NumericWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid smoothing")
+		}
+	case Schedule:
+		if _, ok := meta.(ScheduleWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid schedule")
+		}
+	case Tracking:
+		if _, ok := meta.(TrackingWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid tracking definition")
+		}
+	case Filter:
+		if _, ok := meta.(FilterWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid filter definition")
+		}
+	case Totalizer:
+		if _, ok := meta.(TotalizerWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid totalizer definition")
+		}
+	case Counter:
+		if _, ok := meta.(CounterWaveformMeta); !ok {
+			return fmt.Errorf("missing or invalid counter definition")
+		}
+	case Markov:
+		m, ok := meta.(MarkovWaveformMeta)
+		if !ok {
+			return fmt.Errorf("missing or invalid markov chain")
+		}
+		return m.validate()
+	default:
+		return fmt.Errorf("unrecognized waveform type %v", w.WaveformType)
+	}
+	return nil
+}
+
+// GetReferencedNodes returns the ids of the nodes the
+// waveform derives its values from or is reset by
+func (w *Waveform) GetReferencedNodes() []uuid.UUID {
+	if w.Meta == nil {
+		return nil
+	}
+	switch m := (*w.Meta).(type) {
+	case TrackingWaveformMeta:
+		return []uuid.UUID{m.Source}
+	case FilterWaveformMeta:
+		return []uuid.UUID{m.Source}
+	case TotalizerWaveformMeta:
+		if m.Reset != nil {
+			return []uuid.UUID{m.Source, *m.Reset}
+		}
+		return []uuid.UUID{m.Source}
+	case CounterWaveformMeta:
+		if m.Reset != nil {
+			return []uuid.UUID{m.Source, *m.Reset}
+		}
+		return []uuid.UUID{m.Source}
+	}
+	return nil
+}
+
+func (m *MarkovWaveformMeta) validate() error {
+	count := len(m.States)
+	if count == 0 {
+		return fmt.Errorf("markov chain without states")
+	}
+	if m.Initial < 0 || m.Initial >= count {
+		return fmt.Errorf("initial state %d out of range", m.Initial)
+	}
+	if len(m.Transitions) != count {
+		return fmt.Errorf("expected %d rows of transition weights, got %d", count, len(m.Transitions))
+	}
+	for i, r := range m.Transitions {
+		if len(r) != count {
+			return fmt.Errorf("expected %d transition weights for state %d, got %d", count, i, len(r))
+		}
+		for _, w := range r {
+			if w < 0 {
+				return fmt.Errorf("negative transition weight %f for state %d", w, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package waveformvalue
+
+type IntegerValue struct {
+	Value int32
+}
+
+func (v *IntegerValue) GetValue() any {
+	return v.Value
+}
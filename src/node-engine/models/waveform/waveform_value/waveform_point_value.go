@@ -3,3 +3,22 @@ package waveformvalue
 type WaveformPointValue interface {
 	GetValue() any
 }
+
+// ToNumeric interprets a value as a number, booleans are mapped to 0 & 1
+func ToNumeric(v WaveformPointValue) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	switch t := v.GetValue().(type) {
+	case float64:
+		return t, true
+	case int32:
+		return float64(t), true
+	case bool:
+		if t {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
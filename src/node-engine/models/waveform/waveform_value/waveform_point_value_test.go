@@ -0,0 +1,26 @@
+package waveformvalue
+
+import "testing"
+
+func TestToNumeric(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		value   WaveformPointValue
+		want    float64
+		numeric bool
+	}{
+		{"double", &DoubleValue{Value: 2.5}, 2.5, true},
+		{"integer", &IntegerValue{Value: -3}, -3, true},
+		{"true", &Transition{Value: true}, 1, true},
+		{"false", &Transition{Value: false}, 0, true},
+		{"no value", nil, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, ok := ToNumeric(tc.value)
+
+			if n != tc.want || ok != tc.numeric {
+				t.Errorf("expected %f, %v, got %f, %v", tc.want, tc.numeric, n, ok)
+			}
+		})
+	}
+}
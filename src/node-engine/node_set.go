@@ -0,0 +1,37 @@
+package nodeengine
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// nodeSet is a set of node ids safe for concurrent use
+type nodeSet struct {
+	lock sync.RWMutex
+	ids  map[uuid.UUID]bool
+}
+
+func newNodeSet() *nodeSet {
+	return &nodeSet{
+		ids: make(map[uuid.UUID]bool),
+	}
+}
+
+func (s *nodeSet) Add(id uuid.UUID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ids[id] = true
+}
+
+func (s *nodeSet) Remove(id uuid.UUID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.ids, id)
+}
+
+func (s *nodeSet) Contains(id uuid.UUID) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.ids[id]
+}
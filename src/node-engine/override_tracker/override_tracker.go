@@ -0,0 +1,20 @@
+package overridetracker
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+)
+
+type OverrideTracker interface {
+	init(override.OverrideBehavior)
+	Write(waveformvalue.WaveformPointValue)
+	IsActive() bool
+	GetState() *override.OverrideState
+	Clear() error
+}
+
+func CreateNew(b override.OverrideBehavior) OverrideTracker {
+	t := overrideTrackerImpl{}
+	t.init(b)
+	return &t
+}
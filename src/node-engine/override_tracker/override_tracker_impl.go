@@ -0,0 +1,69 @@
+package overridetracker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+)
+
+type overrideTrackerImpl struct {
+	behavior override.OverrideBehavior
+	lock     sync.Mutex
+	state    *override.OverrideState
+}
+
+func (t *overrideTrackerImpl) init(b override.OverrideBehavior) {
+	t.behavior = b
+	t.state = nil
+}
+
+func (t *overrideTrackerImpl) Write(v waveformvalue.WaveformPointValue) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// every write restarts the override
+	now := time.Now()
+	s := override.OverrideState{
+		Mode:  t.behavior.Mode,
+		Value: v,
+		Since: now,
+	}
+	if t.behavior.Mode == override.Hold {
+		until := now.Add(time.Duration(t.behavior.Duration) * time.Millisecond)
+		s.Until = &until
+	}
+	t.state = &s
+}
+
+func (t *overrideTrackerImpl) IsActive() bool {
+	return t.GetState() != nil
+}
+
+func (t *overrideTrackerImpl) GetState() *override.OverrideState {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.state != nil && t.state.Until != nil && time.Now().After(*t.state.Until) {
+		// hold period elapsed
+		t.state = nil
+	}
+	if t.state == nil {
+		return nil
+	}
+	s := *t.state
+	return &s
+}
+
+func (t *overrideTrackerImpl) Clear() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.state != nil && t.state.Mode == override.Forever {
+		return fmt.Errorf("override can not be cleared")
+	}
+	t.state = nil
+	return nil
+}
@@ -0,0 +1,14 @@
+package qualitycalculator
+
+import "github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+
+type QualityCalculator interface {
+	init(*quality.QualityProfile)
+	GetStatusAtTick(t int64) quality.StatusCode
+}
+
+func CreateNew(p *quality.QualityProfile) QualityCalculator {
+	c := qualityCalculatorImpl{}
+	c.init(p)
+	return &c
+}
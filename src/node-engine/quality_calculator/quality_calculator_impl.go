@@ -0,0 +1,55 @@
+package qualitycalculator
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+)
+
+type qualityCalculatorImpl struct {
+	timeline        []quality.QualityTransition
+	degradation     *quality.QualityDegradation
+	degradedUntil   time.Time
+	degradationRand *rand.Rand
+}
+
+func (c *qualityCalculatorImpl) init(p *quality.QualityProfile) {
+	c.timeline = make([]quality.QualityTransition, 0)
+	if p == nil {
+		return
+	}
+
+	c.timeline = append(c.timeline, p.Timeline...)
+	sort.SliceStable(c.timeline, func(i, j int) bool {
+		return c.timeline[i].Tick < c.timeline[j].Tick
+	})
+	c.degradation = p.Degradation
+	c.degradationRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+func (c *qualityCalculatorImpl) GetStatusAtTick(t int64) quality.StatusCode {
+	// random degradation takes precedence over the timeline
+	if c.degradation != nil {
+		now := time.Now()
+		if now.Before(c.degradedUntil) {
+			return c.degradation.Status
+		}
+		if c.degradationRand.Float64() < c.degradation.Probability {
+			c.degradedUntil = now.Add(time.Duration(c.degradation.Duration) * time.Millisecond)
+			return c.degradation.Status
+		}
+	}
+
+	// every cycle starts with good quality, then the
+	// last transition before the tick is in effect
+	status := quality.Good
+	for _, q := range c.timeline {
+		if q.Tick > t {
+			break
+		}
+		status = q.Status
+	}
+	return status
+}
@@ -0,0 +1,50 @@
+package qualitycalculator_test
+
+import (
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	qualitycalculator "github.com/AndreiLacatos/opc-engine/node-engine/quality_calculator"
+)
+
+func TestGetStatusAtTick(t *testing.T) {
+	// the timeline is sorted by the calculator
+	timeline := &quality.QualityProfile{
+		Timeline: []quality.QualityTransition{
+			{Tick: 600, Status: quality.UncertainLastUsableValue},
+			{Tick: 400, Status: quality.BadSensorFailure},
+		},
+	}
+	for _, tc := range []struct {
+		name    string
+		profile *quality.QualityProfile
+		tick    int64
+		want    quality.StatusCode
+	}{
+		{"no profile", nil, 400, quality.Good},
+		{"start of cycle", timeline, 0, quality.Good},
+		{"before the first transition", timeline, 399, quality.Good},
+		{"at a transition", timeline, 400, quality.BadSensorFailure},
+		{"after the last transition", timeline, 800, quality.UncertainLastUsableValue},
+		{
+			"degraded",
+			&quality.QualityProfile{Timeline: timeline.Timeline, Degradation: &quality.QualityDegradation{Probability: 1, Duration: 100, Status: quality.UncertainSubstituteValue}},
+			0,
+			quality.UncertainSubstituteValue,
+		},
+		{
+			"never degraded",
+			&quality.QualityProfile{Timeline: timeline.Timeline, Degradation: &quality.QualityDegradation{Probability: 0, Duration: 100, Status: quality.UncertainSubstituteValue}},
+			400,
+			quality.BadSensorFailure,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := qualitycalculator.CreateNew(tc.profile)
+
+			if s := c.GetStatusAtTick(tc.tick); s != tc.want {
+				t.Errorf("expected status %x, got %x", tc.want, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package nodeengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	simulationclock "github.com/AndreiLacatos/opc-engine/node-engine/simulation_clock"
+	"go.uber.org/zap"
+)
+
+// the speed of the simulation can change while a scenario waits
+// for its next action, so waits are sliced up & re-evaluated
+const maxScenarioWait = 100 * time.Millisecond
+
+type ScenarioStatus struct {
+	Name    string
+	Running bool
+	Elapsed time.Duration
+}
+
+// scenarioRunner plays the scenarios of the structure one
+// at a time, starting one stops the previous one
+type scenarioRunner struct {
+	lock      sync.Mutex
+	scenarios []scenario.Scenario
+	engine    *valueChangeEngineImpl
+	clock     simulationclock.SimulationClock
+	logger    *zap.Logger
+	running   *runningScenario
+}
+
+type runningScenario struct {
+	name   string
+	start  time.Time
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newScenarioRunner(s []scenario.Scenario, e *valueChangeEngineImpl) *scenarioRunner {
+	return &scenarioRunner{
+		scenarios: s,
+		engine:    e,
+		clock:     e.Clock,
+		logger:    e.Logger.Named("SCENARIO"),
+	}
+}
+
+func (r *scenarioRunner) Start(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var s *scenario.Scenario
+	for i := range r.scenarios {
+		if r.scenarios[i].Name == name {
+			s = &r.scenarios[i]
+		}
+	}
+	if s == nil {
+		return fmt.Errorf("scenario %s not found", name)
+	}
+
+	// effects of the stopped scenario are kept
+	r.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	r.running = &runningScenario{
+		name:   name,
+		start:  r.clock.Now(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go r.run(ctx, *s, *r.running)
+	return nil
+}
+
+// SetScenarios replaces the scenarios, the running
+// scenario carries on with its own actions
+func (r *scenarioRunner) SetScenarios(s []scenario.Scenario) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.scenarios = s
+}
+
+func (r *scenarioRunner) Stop() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.stop() {
+		return fmt.Errorf("no scenario running")
+	}
+	return nil
+}
+
+func (r *scenarioRunner) GetStatus() []ScenarioStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]ScenarioStatus, len(r.scenarios))
+	for i, s := range r.scenarios {
+		res[i] = ScenarioStatus{Name: s.Name}
+		if r.running != nil && r.running.name == s.Name && r.running.isActive() {
+			res[i].Running = true
+			res[i].Elapsed = r.clock.Now().Sub(r.running.start)
+		}
+	}
+	return res
+}
+
+// stop cancels the running scenario & waits for it to quit,
+// the caller holds the lock
+func (r *scenarioRunner) stop() bool {
+	if r.running == nil || !r.running.isActive() {
+		return false
+	}
+	r.running.cancel()
+	<-r.running.done
+	r.logger.Info(fmt.Sprintf("stopped scenario %s", r.running.name))
+	return true
+}
+
+func (r *scenarioRunner) run(ctx context.Context, s scenario.Scenario, rs runningScenario) {
+	defer close(rs.done)
+	r.logger.Info(fmt.Sprintf("starting scenario %s", s.Name))
+	for _, a := range s.Actions {
+		if !r.waitUntil(ctx, rs.start.Add(time.Duration(a.At)*time.Millisecond)) {
+			return
+		}
+		if err := r.apply(a); err != nil {
+			r.logger.Warn(fmt.Sprintf("failed to apply action at %dms of scenario %s, reason: %v", a.At, s.Name, err))
+		}
+	}
+	r.logger.Info(fmt.Sprintf("scenario %s completed", s.Name))
+}
+
+// waitUntil blocks until the simulated time t, it
+// returns false if the scenario was stopped meanwhile
+func (r *scenarioRunner) waitUntil(ctx context.Context, t time.Time) bool {
+	for {
+		d := r.clock.Until(t)
+		if d <= 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(min(d, maxScenarioWait)):
+		}
+	}
+}
+
+func (r *scenarioRunner) apply(a scenario.Action) error {
+	switch a.ActionType {
+	case scenario.SwitchWaveform:
+		return r.engine.SwitchWaveform(a.NodeId, *a.Waveform)
+	case scenario.InjectFault:
+		return r.engine.InjectFault(a.NodeId, *a.Fault)
+	case scenario.ClearFaults:
+		return r.engine.ClearFaults(a.NodeId)
+	case scenario.ChangeSpeed:
+		return r.engine.SetSpeed(a.Speed)
+	case scenario.EnableNodes:
+		return r.engine.EnableNodes(a.NodeId)
+	case scenario.DisableNodes:
+		return r.engine.DisableNodes(a.NodeId)
+	default:
+		return fmt.Errorf("unsupported action %d", a.ActionType)
+	}
+}
+
+func (s *runningScenario) isActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,20 @@
+package serialization
+
+import "github.com/AndreiLacatos/opc-engine/node-engine/models/clock"
+
+type SourceClockModel struct {
+	Offset int64   `json:"offset"`
+	Drift  float64 `json:"drift"`
+	Jitter int64   `json:"jitter"`
+}
+
+func (c *SourceClockModel) ToDomain() *clock.SourceClock {
+	if c == nil {
+		return nil
+	}
+	return &clock.SourceClock{
+		Offset: c.Offset,
+		Drift:  c.Drift,
+		Jitter: c.Jitter,
+	}
+}
@@ -0,0 +1,70 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"go.uber.org/zap"
+)
+
+type ControlLoopModel struct {
+	Setpoint     float64 `json:"setpoint"`
+	Mode         string  `json:"mode"`
+	Output       float64 `json:"output"`
+	Kp           float64 `json:"kp"`
+	Ki           float64 `json:"ki"`
+	Kd           float64 `json:"kd"`
+	OutputMin    float64 `json:"outputMin"`
+	OutputMax    float64 `json:"outputMax"`
+	ProcessGain  float64 `json:"processGain"`
+	TimeConstant int64   `json:"timeConstant"`
+	DeadTime     int64   `json:"deadTime"`
+	Disturbance  *string `json:"disturbance,omitempty"`
+	Noise        float64 `json:"noise"`
+}
+
+func mapControlLoop(p json.RawMessage, l *zap.Logger) template.Template {
+	m := ControlLoopModel{
+		Mode:         "auto",
+		Kp:           1,
+		Ki:           0.1,
+		OutputMax:    100,
+		ProcessGain:  1,
+		TimeConstant: 10000,
+	}
+	if !unmarshalParameters(p, &m, l) {
+		return nil
+	}
+
+	mode := template.Auto
+	switch strings.ToLower(m.Mode) {
+	case "auto":
+		mode = template.Auto
+	case "manual":
+		mode = template.Manual
+	default:
+		l.Warn(fmt.Sprintf("unrecognized control mode %s, defaulting to auto", m.Mode))
+	}
+	if m.OutputMax < m.OutputMin {
+		l.Warn(fmt.Sprintf("output range %f..%f is empty, swapping limits", m.OutputMin, m.OutputMax))
+		m.OutputMin, m.OutputMax = m.OutputMax, m.OutputMin
+	}
+
+	return template.ControlLoopTemplate{
+		Setpoint:     m.Setpoint,
+		Mode:         mode,
+		Output:       m.Output,
+		Kp:           m.Kp,
+		Ki:           m.Ki,
+		Kd:           m.Kd,
+		OutputMin:    m.OutputMin,
+		OutputMax:    m.OutputMax,
+		ProcessGain:  m.ProcessGain,
+		TimeConstant: m.TimeConstant,
+		DeadTime:     m.DeadTime,
+		Disturbance:  mapNodeReference(m.Disturbance, l),
+		Noise:        m.Noise,
+	}
+}
@@ -0,0 +1,70 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"go.uber.org/zap"
+)
+
+func mapTotalizerMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	source := mapNodeReference(m.Source, l)
+	if source == nil {
+		l.Warn("missing totalizer source")
+		return nil
+	}
+
+	meta := waveform.TotalizerWaveformMeta{
+		Source: *source,
+		Scale:  1,
+		Reset:  mapNodeReference(m.Reset, l),
+	}
+	if m.Scale != nil {
+		meta.Scale = *m.Scale
+	}
+	if m.ResetAt != nil {
+		meta.ResetAt = *m.ResetAt
+	}
+	if m.Rollover != nil {
+		meta.Rollover = *m.Rollover
+	}
+
+	var d waveform.WaveformMeta = meta
+	return &d
+}
+
+func mapCounterMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	source := mapNodeReference(m.Source, l)
+	if source == nil {
+		l.Warn("missing counter source")
+		return nil
+	}
+
+	meta := waveform.CounterWaveformMeta{
+		Source: *source,
+		Edge:   waveform.RisingEdge,
+		Reset:  mapNodeReference(m.Reset, l),
+	}
+	if m.Edge != nil {
+		switch strings.ToLower(*m.Edge) {
+		case "rising":
+			meta.Edge = waveform.RisingEdge
+		case "falling":
+			meta.Edge = waveform.FallingEdge
+		case "both":
+			meta.Edge = waveform.BothEdges
+		default:
+			l.Warn(fmt.Sprintf("unrecognized edge %s, defaulting to rising", *m.Edge))
+		}
+	}
+	if m.ResetAt != nil {
+		meta.ResetAt = int32(*m.ResetAt)
+	}
+	if m.Rollover != nil {
+		meta.Rollover = *m.Rollover
+	}
+
+	var d waveform.WaveformMeta = meta
+	return &d
+}
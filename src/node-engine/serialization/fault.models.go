@@ -0,0 +1,85 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	"go.uber.org/zap"
+)
+
+type FaultModel struct {
+	FaultType string  `json:"type"`
+	Start     int64   `json:"start"`
+	Duration  int64   `json:"duration"`
+	Repeat    int64   `json:"repeat"`
+	Value     float64 `json:"value"`
+}
+
+func (f *FaultModel) ToDomain() (*fault.Fault, error) {
+	t, err := mapFaultType(f.FaultType)
+	if err != nil {
+		return nil, err
+	}
+	return &fault.Fault{
+		FaultType: t,
+		Start:     f.Start,
+		Duration:  f.Duration,
+		Repeat:    f.Repeat,
+		Value:     f.Value,
+	}, nil
+}
+
+func mapFaults(m []FaultModel, l *zap.Logger) []fault.Fault {
+	faults := make([]fault.Fault, 0, len(m))
+	for _, f := range m {
+		if mapped, err := f.ToDomain(); err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping fault", err))
+		} else if mapped.Duration <= 0 {
+			l.Warn(fmt.Sprintf("scheduled %s fault has no duration, skipping fault", f.FaultType))
+		} else {
+			faults = append(faults, *mapped)
+		}
+	}
+	return faults
+}
+
+func mapFaultType(t string) (fault.FaultType, error) {
+	switch strings.ToLower(t) {
+	case "stuck":
+		return fault.StuckAt, nil
+	case "drop":
+		return fault.DroppedUpdates, nil
+	case "spike":
+		return fault.Spike, nil
+	case "nan":
+		return fault.NotANumber, nil
+	case "inf":
+		return fault.Infinity, nil
+	case "offset":
+		return fault.Offset, nil
+	case "flatline":
+		return fault.Flatline, nil
+	default:
+		return 0, fmt.Errorf("unrecognized fault type %s", t)
+	}
+}
+
+func MapFaultType(t fault.FaultType) string {
+	switch t {
+	case fault.StuckAt:
+		return "stuck"
+	case fault.DroppedUpdates:
+		return "drop"
+	case fault.Spike:
+		return "spike"
+	case fault.NotANumber:
+		return "nan"
+	case fault.Infinity:
+		return "inf"
+	case fault.Offset:
+		return "offset"
+	default:
+		return "flatline"
+	}
+}
@@ -0,0 +1,55 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"go.uber.org/zap"
+)
+
+func mapFilterMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	source := mapNodeReference(m.Source, l)
+	if source == nil {
+		l.Warn("missing filter source")
+		return nil
+	}
+	if m.Filter == nil {
+		l.Warn("missing filter type")
+		return nil
+	}
+
+	meta := waveform.FilterWaveformMeta{
+		Source:       *source,
+		TimeConstant: 1000,
+		Alpha:        0.5,
+	}
+	switch strings.ToLower(*m.Filter) {
+	case "pt1":
+		meta.Filter = waveform.FirstOrderLagFilter
+	case "deadtime":
+		meta.Filter = waveform.DeadTimeFilter
+	case "movingaverage":
+		meta.Filter = waveform.MovingAverageFilter
+	case "exponential":
+		meta.Filter = waveform.ExponentialSmoothingFilter
+	default:
+		l.Warn(fmt.Sprintf("unrecognized filter type %s", *m.Filter))
+		return nil
+	}
+	if m.TimeConstant != nil {
+		meta.TimeConstant = *m.TimeConstant
+	}
+	if m.Delay != nil {
+		meta.Delay = *m.Delay
+	}
+	if m.Window != nil {
+		meta.Window = *m.Window
+	}
+	if m.Alpha != nil {
+		meta.Alpha = *m.Alpha
+	}
+
+	var d waveform.WaveformMeta = meta
+	return &d
+}
@@ -0,0 +1,102 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type MarkovStateModel struct {
+	Name  string          `json:"name"`
+	Value *float64        `json:"value,omitempty"`
+	Dwell *DwellTimeModel `json:"dwell,omitempty"`
+}
+
+type DwellTimeModel struct {
+	Distribution string `json:"distribution"`
+	Mean         int64  `json:"mean"`
+	Min          int64  `json:"min"`
+	Max          int64  `json:"max"`
+	StdDev       int64  `json:"stdDev"`
+}
+
+func mapMarkovMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	valueType := waveform.IntegerValue
+	if m.ValueType != nil {
+		switch strings.ToLower(*m.ValueType) {
+		case "boolean":
+			valueType = waveform.BooleanValue
+		case "integer", "enum":
+			valueType = waveform.IntegerValue
+		default:
+			l.Warn(fmt.Sprintf("unrecognized markov chain value type %s, defaulting to integer", *m.ValueType))
+		}
+	}
+
+	// the chain is mapped as given, its consistency is checked
+	// when the waveform is validated so that the reason is reported
+	states := make([]waveform.MarkovState, len(m.States))
+	for i, s := range m.States {
+		// states without value are numbered in order
+		value := float64(i)
+		if s.Value != nil {
+			value = *s.Value
+		}
+		states[i] = waveform.MarkovState{
+			Name:  s.Name,
+			Value: mapMarkovValue(value, valueType),
+			Dwell: mapDwellTime(s.Dwell, l),
+		}
+	}
+
+	initial := 0
+	if m.Initial != nil {
+		initial = int(*m.Initial)
+	}
+
+	var d waveform.WaveformMeta = waveform.MarkovWaveformMeta{
+		ValueType:   valueType,
+		States:      states,
+		Transitions: m.Transitions,
+		Initial:     initial,
+		Seed:        m.Seed,
+	}
+	return &d
+}
+
+func mapMarkovValue(v float64, t waveform.ValueType) waveformvalue.WaveformPointValue {
+	if t == waveform.BooleanValue {
+		return &waveformvalue.Transition{Value: v != 0}
+	}
+	return &waveformvalue.IntegerValue{Value: int32(v)}
+}
+
+func mapDwellTime(m *DwellTimeModel, l *zap.Logger) waveform.DwellTime {
+	if m == nil {
+		// without dwell time the state is left on the next tick
+		return waveform.DwellTime{Distribution: waveform.FixedDwell}
+	}
+	d := waveform.DwellTime{
+		Mean:   m.Mean,
+		Min:    m.Min,
+		Max:    m.Max,
+		StdDev: m.StdDev,
+	}
+	switch strings.ToLower(m.Distribution) {
+	case "", "fixed":
+		d.Distribution = waveform.FixedDwell
+	case "uniform":
+		d.Distribution = waveform.UniformDwell
+	case "exponential":
+		d.Distribution = waveform.ExponentialDwell
+	case "normal":
+		d.Distribution = waveform.NormalDwell
+	default:
+		l.Warn(fmt.Sprintf("unrecognized dwell time distribution %s, defaulting to fixed", m.Distribution))
+		d.Distribution = waveform.FixedDwell
+	}
+	return d
+}
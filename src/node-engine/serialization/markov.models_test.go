@@ -0,0 +1,98 @@
+package serialization
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+func TestMapMarkovMeta(t *testing.T) {
+	const transitions = `"transitions": [[0, 1], [3, 1]]`
+	fixed := waveform.DwellTime{Distribution: waveform.FixedDwell}
+	for _, tc := range []struct {
+		name string
+		meta string
+		want *waveform.MarkovWaveformMeta
+		err  string
+	}{
+		{
+			"states numbered in order",
+			`{"states": [{"name": "Idle"}, {"name": "Running"}], ` + transitions + `}`,
+			&waveform.MarkovWaveformMeta{
+				ValueType: waveform.IntegerValue,
+				States: []waveform.MarkovState{
+					{Name: "Idle", Value: &waveformvalue.IntegerValue{Value: 0}, Dwell: fixed},
+					{Name: "Running", Value: &waveformvalue.IntegerValue{Value: 1}, Dwell: fixed},
+				},
+				Transitions: [][]float64{{0, 1}, {3, 1}},
+			},
+			"",
+		},
+		{
+			"boolean states with values, dwell times & initial state",
+			`{"valueType": "boolean", "initial": 1, "seed": 7, ` + transitions + `, "states": [
+				{"name": "Off", "value": 1, "dwell": {"distribution": "uniform", "min": 100, "max": 200}},
+				{"name": "On", "value": 0, "dwell": {"distribution": "Exponential", "mean": 500}}
+			]}`,
+			&waveform.MarkovWaveformMeta{
+				ValueType: waveform.BooleanValue,
+				States: []waveform.MarkovState{
+					{Name: "Off", Value: &waveformvalue.Transition{Value: true}, Dwell: waveform.DwellTime{Distribution: waveform.UniformDwell, Min: 100, Max: 200}},
+					{Name: "On", Value: &waveformvalue.Transition{Value: false}, Dwell: waveform.DwellTime{Distribution: waveform.ExponentialDwell, Mean: 500}},
+				},
+				Transitions: [][]float64{{0, 1}, {3, 1}},
+				Initial:     1,
+				Seed:        ptr(int64(7)),
+			},
+			"",
+		},
+		{
+			"unknown value type & distribution fall back to defaults",
+			`{"valueType": "text", ` + transitions + `, "states": [{"name": "A", "dwell": {"distribution": "poisson", "mean": 100}}, {"name": "B", "value": 4}]}`,
+			&waveform.MarkovWaveformMeta{
+				ValueType: waveform.IntegerValue,
+				States: []waveform.MarkovState{
+					{Name: "A", Value: &waveformvalue.IntegerValue{Value: 0}, Dwell: waveform.DwellTime{Distribution: waveform.FixedDwell, Mean: 100}},
+					{Name: "B", Value: &waveformvalue.IntegerValue{Value: 4}, Dwell: fixed},
+				},
+				Transitions: [][]float64{{0, 1}, {3, 1}},
+			},
+			"",
+		},
+		{"no states", `{"states": [], "transitions": []}`, nil, "without states"},
+		{"missing row of weights", `{"states": [{"name": "A"}, {"name": "B"}], "transitions": [[1, 1]]}`, nil, "expected 2 rows of transition weights"},
+		{"missing weight", `{"states": [{"name": "A"}, {"name": "B"}], "transitions": [[1, 1], [1]]}`, nil, "expected 2 transition weights for state 1"},
+		{"negative weight", `{"states": [{"name": "A"}, {"name": "B"}], "transitions": [[1, -1], [1, 1]]}`, nil, "negative transition weight"},
+		{"initial state out of range", `{"initial": 5, "states": [{"name": "A"}, {"name": "B"}], ` + transitions + `}`, nil, "initial state 5 out of range"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m WaveformMetaModel
+			if err := json.Unmarshal([]byte(tc.meta), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			meta := mapMarkovMeta(&m, zap.NewNop())
+
+			if meta == nil {
+				t.Fatal("expected a markov chain, got none")
+			}
+			w := waveform.Waveform{Duration: 1000, TickFrequency: 100, WaveformType: waveform.Markov, Meta: meta}
+			if err := w.Validate(); tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(*meta, waveform.WaveformMeta(*tc.want)) {
+				t.Errorf("expected %+v, got %+v", *tc.want, *meta)
+			}
+		})
+	}
+}
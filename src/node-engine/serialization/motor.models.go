@@ -0,0 +1,64 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"go.uber.org/zap"
+)
+
+type MotorModel struct {
+	Running             bool    `json:"running"`
+	RatedSpeed          float64 `json:"ratedSpeed"`
+	AccelerationTime    int64   `json:"accelerationTime"`
+	DecelerationTime    int64   `json:"decelerationTime"`
+	FeedbackDelay       int64   `json:"feedbackDelay"`
+	RatedCurrent        float64 `json:"ratedCurrent"`
+	StartingCurrent     float64 `json:"startingCurrent"`
+	NoLoadCurrent       float64 `json:"noLoadCurrent"`
+	Load                float64 `json:"load"`
+	LoadSource          *string `json:"loadSource,omitempty"`
+	AmbientTemperature  float64 `json:"ambientTemperature"`
+	TemperatureRise     float64 `json:"temperatureRise"`
+	ThermalTimeConstant int64   `json:"thermalTimeConstant"`
+}
+
+func mapMotor(p json.RawMessage, l *zap.Logger) template.Template {
+	m := MotorModel{
+		RatedSpeed:          1450,
+		AccelerationTime:    3000,
+		DecelerationTime:    5000,
+		FeedbackDelay:       500,
+		RatedCurrent:        10,
+		StartingCurrent:     6,
+		NoLoadCurrent:       0.3,
+		Load:                0.8,
+		AmbientTemperature:  25,
+		TemperatureRise:     60,
+		ThermalTimeConstant: 600000,
+	}
+	if !unmarshalParameters(p, &m, l) {
+		return nil
+	}
+	if m.RatedSpeed <= 0 || m.RatedCurrent <= 0 {
+		l.Warn(fmt.Sprintf("invalid motor ratings, speed %f & current %f", m.RatedSpeed, m.RatedCurrent))
+		return nil
+	}
+
+	return template.MotorTemplate{
+		Running:             m.Running,
+		RatedSpeed:          m.RatedSpeed,
+		AccelerationTime:    m.AccelerationTime,
+		DecelerationTime:    m.DecelerationTime,
+		FeedbackDelay:       m.FeedbackDelay,
+		RatedCurrent:        m.RatedCurrent,
+		StartingCurrent:     m.StartingCurrent,
+		NoLoadCurrent:       m.NoLoadCurrent,
+		Load:                m.Load,
+		LoadSource:          mapNodeReference(m.LoadSource, l),
+		AmbientTemperature:  m.AmbientTemperature,
+		TemperatureRise:     m.TemperatureRise,
+		ThermalTimeConstant: m.ThermalTimeConstant,
+	}
+}
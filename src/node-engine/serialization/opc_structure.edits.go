@@ -0,0 +1,145 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Clone returns a deep copy of the structure, so that it can be edited
+// without affecting the structure it was copied from
+func (m *OpcStructureModel) Clone() (*OpcStructureModel, error) {
+	content, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var clone OpcStructureModel
+	if err := json.Unmarshal(content, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// FindNode returns the node with the given id & its parent,
+// the parent of the root is nil
+func (m *OpcStructureModel) FindNode(id string) (*OpcStructureNodeModel, *OpcStructureNodeModel, error) {
+	if n, p := findNode(&m.Root, nil, id); n != nil {
+		return n, p, nil
+	}
+	return nil, nil, fmt.Errorf("node %s not found", id)
+}
+
+// FindNodeByPath returns the node at the browse path, the labels of the nodes
+// from the root down to it separated by slashes, e.g. "Root/Line 1/Speed"
+func (m *OpcStructureModel) FindNodeByPath(path string) (*OpcStructureNodeModel, error) {
+	labels := strings.Split(strings.Trim(path, "/"), "/")
+	if labels[0] != m.Root.Label {
+		return nil, fmt.Errorf("node %s not found", path)
+	}
+	n := &m.Root
+	for _, label := range labels[1:] {
+		var next *OpcStructureNodeModel
+		if n.Children != nil {
+			for i := range *n.Children {
+				if (*n.Children)[i].Label == label {
+					next = &(*n.Children)[i]
+					break
+				}
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("node %s not found", path)
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// AddNode adds the node along with its subtree under the parent container,
+// the whole subtree is rejected if any of its nodes is invalid
+func (m *OpcStructureModel) AddNode(parentId string, n OpcStructureNodeModel) error {
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	if existing, _ := findNode(&m.Root, nil, n.Id); existing != nil {
+		return fmt.Errorf("node %s already exists", n.Id)
+	}
+	parent, err := m.findContainer(parentId)
+	if err != nil {
+		return err
+	}
+	if parent.Children == nil {
+		parent.Children = &[]OpcStructureNodeModel{}
+	}
+	*parent.Children = append(*parent.Children, n)
+	return nil
+}
+
+// RemoveNode removes the node along with its subtree & returns it
+func (m *OpcStructureModel) RemoveNode(id string) (*OpcStructureNodeModel, error) {
+	n, parent, err := m.FindNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("the root node can not be removed")
+	}
+	removed := *n
+	children := *parent.Children
+	for i := range children {
+		if &children[i] == n {
+			*parent.Children = append(children[:i:i], children[i+1:]...)
+			break
+		}
+	}
+	return &removed, nil
+}
+
+// MoveNode moves the node along with its subtree under another container
+func (m *OpcStructureModel) MoveNode(id string, parentId string) (*OpcStructureNodeModel, error) {
+	n, _, err := m.FindNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if descendant, _ := findNode(n, nil, parentId); descendant != nil {
+		return nil, fmt.Errorf("%s can not be moved under itself or its subtree", n.Label)
+	}
+	if _, err := m.findContainer(parentId); err != nil {
+		return nil, err
+	}
+
+	removed, err := m.RemoveNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.AddNode(parentId, *removed); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (m *OpcStructureModel) findContainer(id string) (*OpcStructureNodeModel, error) {
+	n, _, err := m.FindNode(id)
+	if err != nil {
+		return nil, err
+	}
+	if n.NodeType != "container" {
+		return nil, fmt.Errorf("nodes can only be added under containers, %s is a %s node", n.Label, n.NodeType)
+	}
+	return n, nil
+}
+
+func findNode(n *OpcStructureNodeModel, parent *OpcStructureNodeModel, id string) (*OpcStructureNodeModel, *OpcStructureNodeModel) {
+	if strings.EqualFold(n.Id, id) {
+		return n, parent
+	}
+	if n.Children == nil {
+		return nil, nil
+	}
+	for i := range *n.Children {
+		if found, p := findNode(&(*n.Children)[i], n, id); found != nil {
+			return found, p
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,195 @@
+package serialization
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	rootId   = "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e6f"
+	lineId   = "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	speedId  = "1b2c3d4e-5f6a-4b7c-8d9e-0f1a2b3c4d5e"
+	valveId  = "3d4e5f6a-7b8c-4d9e-8f1a-2b3c4d5e6f7a"
+	addedId  = "4e5f6a7b-8c9d-4e0f-9a2b-3c4d5e6f7a8b"
+	nestedId = "5f6a7b8c-9d0e-4f1a-8b3c-4d5e6f7a8b9c"
+)
+
+// makeStructure returns Root/Line/Speed & Root/Valve
+func makeStructure(t *testing.T) *OpcStructureModel {
+	var m OpcStructureModel
+	if err := json.Unmarshal([]byte(`{"root": {"id": "`+rootId+`", "label": "Root", "type": "container", "children": [
+		{"id": "`+lineId+`", "label": "Line", "type": "container", "children": [
+			{"id": "`+speedId+`", "label": "Speed", "type": "value", "waveform": {"duration": 1000, "tickFrequency": 100, "type": "transitions"}}
+		]},
+		{"id": "`+valveId+`", "label": "Valve", "type": "value", "waveform": {"duration": 1000, "tickFrequency": 100, "type": "transitions"}}
+	]}}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	return &m
+}
+
+func parseNode(t *testing.T, n string) OpcStructureNodeModel {
+	var m OpcStructureNodeModel
+	if err := json.Unmarshal([]byte(n), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAddNode(t *testing.T) {
+	const waveform = `"waveform": {"duration": 1000, "tickFrequency": 100, "type": "transitions"}`
+	for _, tc := range []struct {
+		name     string
+		parentId string
+		node     string
+		err      string
+	}{
+		{"value node", lineId, `{"id": "` + addedId + `", "label": "Added", "type": "value", ` + waveform + `}`, ""},
+		{
+			"container with children",
+			rootId,
+			`{"id": "` + addedId + `", "label": "Added", "type": "container", "children": [{"id": "` + nestedId + `", "label": "Nested", "type": "value", ` + waveform + `}]}`,
+			"",
+		},
+		{"existing id", rootId, `{"id": "` + speedId + `", "label": "Speed", "type": "value", ` + waveform + `}`, "already exists"},
+		{"unknown parent", addedId, `{"id": "` + nestedId + `", "label": "Nested", "type": "value", ` + waveform + `}`, "not found"},
+		{"under a value node", valveId, `{"id": "` + addedId + `", "label": "Added", "type": "value", ` + waveform + `}`, "only be added under containers"},
+		{"invalid id", rootId, `{"id": "pump", "label": "Added", "type": "value", ` + waveform + `}`, "not a valid node id"},
+		{"value node without waveform", rootId, `{"id": "` + addedId + `", "label": "Added", "type": "value"}`, "missing waveform"},
+		{"unknown type", rootId, `{"id": "` + addedId + `", "label": "Added", "type": "folder"}`, "unrecognized node type"},
+		{"invalid template", rootId, `{"id": "` + addedId + `", "label": "Added", "type": "template", "template": {"type": "boiler", "tickFrequency": 100}}`, "invalid template"},
+		{
+			"child with invalid id",
+			rootId,
+			`{"id": "` + addedId + `", "label": "Added", "type": "container", "children": [{"id": "pump", "label": "Nested", "type": "value", ` + waveform + `}]}`,
+			"not a valid node id",
+		},
+		{
+			"grandchild without waveform",
+			rootId,
+			`{"id": "` + addedId + `", "label": "Added", "type": "container", "children": [
+				{"id": "` + nestedId + `", "label": "Nested", "type": "container", "children": [
+					{"id": "6a7b8c9d-0e1f-4a2b-9c4d-5e6f7a8b9c0d", "label": "Deep", "type": "value"}
+				]}
+			]}`,
+			"Added: Nested: missing waveform for Deep",
+		},
+		{
+			"child of unknown type",
+			rootId,
+			`{"id": "` + addedId + `", "label": "Added", "type": "container", "children": [{"id": "` + nestedId + `", "label": "Nested", "type": "sensor"}]}`,
+			"unrecognized node type",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := makeStructure(t)
+			n := parseNode(t, tc.node)
+
+			err := m.AddNode(tc.parentId, n)
+
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				if _, _, err := m.FindNode(addedId); err == nil {
+					t.Errorf("rejected node %s was added", addedId)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			_, parent, err := m.FindNode(n.Id)
+			if err != nil || parent.Id != tc.parentId {
+				t.Errorf("expected %s under %s, got %v, %v", n.Id, tc.parentId, parent, err)
+			}
+		})
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		id      string
+		err     string
+		removed []string
+	}{
+		{"value node", valveId, "", []string{valveId}},
+		{"container along with its subtree", lineId, "", []string{lineId, speedId}},
+		{"nested value node", speedId, "", []string{speedId}},
+		{"root", rootId, "root node can not be removed", nil},
+		{"unknown node", addedId, "not found", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := makeStructure(t)
+
+			n, err := m.RemoveNode(tc.id)
+
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if n.Id != tc.id {
+				t.Errorf("expected %s to be returned, got %s", tc.id, n.Id)
+			}
+			for _, id := range tc.removed {
+				if _, _, err := m.FindNode(id); err == nil {
+					t.Errorf("expected %s to be removed", id)
+				}
+			}
+			for _, id := range []string{rootId, lineId, speedId, valveId} {
+				if _, _, err := m.FindNode(id); err != nil && !slices.Contains(tc.removed, id) {
+					t.Errorf("expected %s to be kept", id)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveNode(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		id       string
+		parentId string
+		err      string
+	}{
+		{"value node to another container", speedId, rootId, ""},
+		{"value node under a container", valveId, lineId, ""},
+		{"under itself", lineId, lineId, "under itself or its subtree"},
+		{"under its own subtree", rootId, lineId, "under itself or its subtree"},
+		{"under a value node", lineId, valveId, "only be added under containers"},
+		{"unknown node", addedId, rootId, "not found"},
+		{"unknown parent", speedId, addedId, "not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := makeStructure(t)
+
+			_, err := m.MoveNode(tc.id, tc.parentId)
+
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				if !reflect.DeepEqual(m, makeStructure(t)) {
+					t.Errorf("expected the structure to be left unchanged, got %+v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			_, parent, err := m.FindNode(tc.id)
+			if err != nil || parent.Id != tc.parentId {
+				t.Errorf("expected %s under %s, got %v, %v", tc.id, tc.parentId, parent, err)
+			}
+		})
+	}
+}
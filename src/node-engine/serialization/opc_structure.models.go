@@ -10,20 +10,29 @@ import (
 )
 
 type OpcStructureModel struct {
-	Root OpcStructureNodeModel `json:"root"`
+	Root      OpcStructureNodeModel `json:"root"`
+	Scenarios []ScenarioModel       `json:"scenarios,omitempty"`
+	Triggers  []TriggerModel        `json:"triggers,omitempty"`
 }
 
 type OpcStructureNodeModel struct {
-	Id       string                   `json:"id"`
-	Label    string                   `json:"label"`
-	NodeType string                   `json:"type"`
-	Children *[]OpcStructureNodeModel `json:"children,omitempty"`
-	Waveform *WaveformModel           `json:"waveform,omitempty"`
+	Id          string                   `json:"id"`
+	Label       string                   `json:"label"`
+	NodeType    string                   `json:"type"`
+	Children    *[]OpcStructureNodeModel `json:"children,omitempty"`
+	Waveform    *WaveformModel           `json:"waveform,omitempty"`
+	Faults      []FaultModel             `json:"faults,omitempty"`
+	Quality     *QualityModel            `json:"quality,omitempty"`
+	SourceClock *SourceClockModel        `json:"sourceClock,omitempty"`
+	Override    *OverrideModel           `json:"override,omitempty"`
+	Template    *TemplateModel           `json:"template,omitempty"`
 }
 
 func (m *OpcStructureModel) ToDomain(l *zap.Logger) opc.OpcStructure {
 	return opc.OpcStructure{
-		Root: *m.Root.ToDomain(l.Named("mapper")).(*opcnode.OpcContainerNode),
+		Root:      *m.Root.ToDomain(l.Named("mapper")).(*opcnode.OpcContainerNode),
+		Scenarios: mapScenarios(m.Scenarios, l.Named("mapper")),
+		Triggers:  mapTriggers(m.Triggers, l.Named("mapper")),
 	}
 }
 
@@ -36,9 +45,11 @@ func (n *OpcStructureNodeModel) ToDomain(l *zap.Logger) opcnode.OpcStructureNode
 	switch n.NodeType {
 	case "container":
 		mappedChildren := make([]opcnode.OpcStructureNode, 0)
-		for _, v := range *n.Children {
-			if mapped := v.ToDomain(l); mapped != nil {
-				mappedChildren = append(mappedChildren, mapped)
+		if n.Children != nil {
+			for _, v := range *n.Children {
+				if mapped := v.ToDomain(l); mapped != nil {
+					mappedChildren = append(mappedChildren, mapped)
+				}
 			}
 		}
 		return &opcnode.OpcContainerNode{
@@ -47,13 +58,54 @@ func (n *OpcStructureNodeModel) ToDomain(l *zap.Logger) opcnode.OpcStructureNode
 			Children: mappedChildren,
 		}
 	case "value":
+		if n.Waveform == nil {
+			l.Warn(fmt.Sprintf("missing waveform for %s, skipping node", n.Label))
+			return nil
+		}
 		return &opcnode.OpcValueNode{
-			Id:       id,
-			Label:    n.Label,
-			Waveform: n.Waveform.ToDomain(l),
+			Id:          id,
+			Label:       n.Label,
+			Waveform:    n.Waveform.ToDomain(l),
+			Faults:      mapFaults(n.Faults, l),
+			Quality:     n.Quality.ToDomain(l),
+			SourceClock: n.SourceClock.ToDomain(),
+			Override:    n.Override.ToDomain(l),
 		}
+	case "template":
+		return n.Template.ToDomain(id, n.Label, l)
 	default:
 		l.Warn(fmt.Sprintf("unrecognized node type %s, skipping node", n.NodeType))
 		return nil
 	}
 }
+
+// Validate checks that every node of the subtree can be mapped, ToDomain
+// skips the nodes it can not map & only warns about them
+func (n *OpcStructureNodeModel) Validate() error {
+	if _, err := uuid.Parse(n.Id); err != nil {
+		return fmt.Errorf("%s is not a valid node id", n.Id)
+	}
+	switch n.NodeType {
+	case "container":
+		if n.Children == nil {
+			return nil
+		}
+		for i := range *n.Children {
+			if err := (*n.Children)[i].Validate(); err != nil {
+				return fmt.Errorf("%s: %w", n.Label, err)
+			}
+		}
+	case "value":
+		if n.Waveform == nil {
+			return fmt.Errorf("missing waveform for %s", n.Label)
+		}
+	case "template":
+		// the template mapper reports the reason as a warning
+		if n.ToDomain(zap.NewNop()) == nil {
+			return fmt.Errorf("invalid template definition for %s", n.Label)
+		}
+	default:
+		return fmt.Errorf("unrecognized node type %s for %s", n.NodeType, n.Label)
+	}
+	return nil
+}
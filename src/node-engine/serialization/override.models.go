@@ -0,0 +1,53 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/override"
+	"go.uber.org/zap"
+)
+
+type OverrideModel struct {
+	Mode     string `json:"mode"`
+	Duration int64  `json:"duration"`
+}
+
+func (o *OverrideModel) ToDomain(l *zap.Logger) *override.OverrideBehavior {
+	if o == nil {
+		return nil
+	}
+
+	var m override.OverrideMode
+	switch strings.ToLower(o.Mode) {
+	case "hold":
+		m = override.Hold
+		if o.Duration <= 0 {
+			l.Warn("missing hold duration, node is not writable")
+			return nil
+		}
+	case "untilreset":
+		m = override.UntilReset
+	case "forever":
+		m = override.Forever
+	default:
+		l.Warn(fmt.Sprintf("unrecognized override mode %s, node is not writable", o.Mode))
+		return nil
+	}
+
+	return &override.OverrideBehavior{
+		Mode:     m,
+		Duration: o.Duration,
+	}
+}
+
+func MapOverrideMode(m override.OverrideMode) string {
+	switch m {
+	case override.Hold:
+		return "hold"
+	case override.UntilReset:
+		return "untilReset"
+	default:
+		return "forever"
+	}
+}
@@ -0,0 +1,75 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"go.uber.org/zap"
+)
+
+type PackMLModel struct {
+	InitialState    string           `json:"initialState"`
+	DefaultDuration int64            `json:"defaultDuration"`
+	Durations       map[string]int64 `json:"durations,omitempty"`
+	ExecuteDuration int64            `json:"executeDuration"`
+	FaultRate       float64          `json:"faultRate"`
+}
+
+var packMLStateNames = map[string]template.PackMLState{
+	"clearing":     template.Clearing,
+	"stopped":      template.Stopped,
+	"starting":     template.Starting,
+	"idle":         template.Idle,
+	"suspended":    template.Suspended,
+	"execute":      template.Execute,
+	"stopping":     template.Stopping,
+	"aborting":     template.Aborting,
+	"aborted":      template.Aborted,
+	"holding":      template.Holding,
+	"held":         template.Held,
+	"unholding":    template.Unholding,
+	"suspending":   template.Suspending,
+	"unsuspending": template.Unsuspending,
+	"resetting":    template.Resetting,
+	"completing":   template.Completing,
+	"complete":     template.Complete,
+}
+
+func mapPackML(p json.RawMessage, l *zap.Logger) template.Template {
+	m := PackMLModel{
+		InitialState:    "stopped",
+		DefaultDuration: 1000,
+	}
+	if !unmarshalParameters(p, &m, l) {
+		return nil
+	}
+
+	initialState, found := packMLStateNames[strings.ToLower(m.InitialState)]
+	if !found || initialState.IsActing() {
+		l.Warn(fmt.Sprintf("invalid initial state %s, defaulting to stopped", m.InitialState))
+		initialState = template.Stopped
+	}
+
+	durations := make(map[template.PackMLState]int64)
+	for _, s := range packMLStateNames {
+		if s.IsActing() {
+			durations[s] = m.DefaultDuration
+		}
+	}
+	for n, d := range m.Durations {
+		if s, found := packMLStateNames[strings.ToLower(n)]; found && s.IsActing() {
+			durations[s] = d
+		} else {
+			l.Warn(fmt.Sprintf("%s is not an acting state, ignoring its duration", n))
+		}
+	}
+
+	return template.PackMLTemplate{
+		InitialState:    initialState,
+		Durations:       durations,
+		ExecuteDuration: m.ExecuteDuration,
+		FaultRate:       m.FaultRate,
+	}
+}
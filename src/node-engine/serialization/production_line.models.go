@@ -0,0 +1,75 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"go.uber.org/zap"
+)
+
+type ProductionLineModel struct {
+	Stations []StationModel `json:"stations"`
+}
+
+type StationModel struct {
+	Name           string  `json:"name"`
+	CycleTime      int64   `json:"cycleTime"`
+	Variance       int64   `json:"variance"`
+	ScrapRate      float64 `json:"scrapRate"`
+	MTBF           int64   `json:"mtbf"`
+	MTTR           int64   `json:"mttr"`
+	BufferCapacity *int32  `json:"bufferCapacity,omitempty"`
+}
+
+func mapProductionLine(p json.RawMessage, l *zap.Logger) template.Template {
+	m := ProductionLineModel{}
+	if !unmarshalParameters(p, &m, l) {
+		return nil
+	}
+	if len(m.Stations) == 0 {
+		l.Warn("production line without stations")
+		return nil
+	}
+
+	names := make(map[string]bool)
+	stations := make([]template.Station, 0, len(m.Stations))
+	for _, s := range m.Stations {
+		if s.Name == "" || names[s.Name] {
+			l.Warn(fmt.Sprintf("station name %q is missing or not unique, skipping station", s.Name))
+			continue
+		}
+		if s.CycleTime <= 0 {
+			l.Warn(fmt.Sprintf("invalid cycle time %d for station %s, skipping station", s.CycleTime, s.Name))
+			continue
+		}
+		names[s.Name] = true
+
+		// buffers hold a single part by default
+		station := template.Station{
+			Name:           s.Name,
+			CycleTime:      s.CycleTime,
+			Variance:       s.Variance,
+			ScrapRate:      s.ScrapRate,
+			MTBF:           s.MTBF,
+			MTTR:           s.MTTR,
+			BufferCapacity: 1,
+		}
+		if s.BufferCapacity != nil {
+			if *s.BufferCapacity < 1 {
+				l.Warn(fmt.Sprintf("invalid buffer capacity %d for station %s, using 1", *s.BufferCapacity, s.Name))
+			} else {
+				station.BufferCapacity = *s.BufferCapacity
+			}
+		}
+		stations = append(stations, station)
+	}
+
+	if len(stations) == 0 {
+		l.Warn("production line without valid stations")
+		return nil
+	}
+	return template.ProductionLineTemplate{
+		Stations: stations,
+	}
+}
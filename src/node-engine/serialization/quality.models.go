@@ -0,0 +1,82 @@
+package serialization
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/quality"
+	"go.uber.org/zap"
+)
+
+type QualityModel struct {
+	Timeline    []QualityTransitionModel `json:"timeline"`
+	Degradation *QualityDegradationModel `json:"degradation,omitempty"`
+}
+
+type QualityTransitionModel struct {
+	Tick   int64  `json:"tick"`
+	Status string `json:"status"`
+}
+
+type QualityDegradationModel struct {
+	Probability float64 `json:"probability"`
+	Duration    int64   `json:"duration"`
+	Status      string  `json:"status"`
+}
+
+func (q *QualityModel) ToDomain(l *zap.Logger) *quality.QualityProfile {
+	if q == nil {
+		return nil
+	}
+
+	timeline := make([]quality.QualityTransition, 0, len(q.Timeline))
+	for _, t := range q.Timeline {
+		s, err := mapStatusCode(t.Status)
+		if err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping quality transition", err))
+			continue
+		}
+		timeline = append(timeline, quality.QualityTransition{
+			Tick:   t.Tick,
+			Status: s,
+		})
+	}
+
+	var degradation *quality.QualityDegradation
+	if q.Degradation != nil {
+		if s, err := mapStatusCode(q.Degradation.Status); err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping quality degradation", err))
+		} else {
+			degradation = &quality.QualityDegradation{
+				Probability: q.Degradation.Probability,
+				Duration:    q.Degradation.Duration,
+				Status:      s,
+			}
+		}
+	}
+
+	return &quality.QualityProfile{
+		Timeline:    timeline,
+		Degradation: degradation,
+	}
+}
+
+func mapStatusCode(s string) (quality.StatusCode, error) {
+	if c, found := quality.StatusCodeNames[s]; found {
+		return c, nil
+	}
+	// accept raw status codes as well, e.g. 0x808C0000
+	if c, err := strconv.ParseUint(s, 0, 32); err == nil {
+		return quality.StatusCode(c), nil
+	}
+	return quality.Good, fmt.Errorf("unrecognized status code %s", s)
+}
+
+func MapStatusCode(c quality.StatusCode) string {
+	for name, code := range quality.StatusCodeNames {
+		if code == c {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%08X", uint32(c))
+}
@@ -0,0 +1,132 @@
+package serialization
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ScenarioModel struct {
+	Name    string                `json:"name"`
+	Actions []ScenarioActionModel `json:"actions"`
+}
+
+type ScenarioActionModel struct {
+	At       int64          `json:"at"`
+	Action   string         `json:"action"`
+	NodeId   *string        `json:"nodeId,omitempty"`
+	Waveform *WaveformModel `json:"waveform,omitempty"`
+	Fault    *FaultModel    `json:"fault,omitempty"`
+	Speed    *float64       `json:"speed,omitempty"`
+}
+
+func mapScenarios(m []ScenarioModel, l *zap.Logger) []scenario.Scenario {
+	scenarios := make([]scenario.Scenario, 0, len(m))
+	names := make(map[string]bool)
+	for _, s := range m {
+		if s.Name == "" {
+			l.Warn("scenario has no name, skipping scenario")
+			continue
+		}
+		if names[s.Name] {
+			l.Warn(fmt.Sprintf("duplicate scenario %s, skipping scenario", s.Name))
+			continue
+		}
+		names[s.Name] = true
+		scenarios = append(scenarios, s.ToDomain(l))
+	}
+	return scenarios
+}
+
+func (s *ScenarioModel) ToDomain(l *zap.Logger) scenario.Scenario {
+	actions := make([]scenario.Action, 0, len(s.Actions))
+	for _, a := range s.Actions {
+		if mapped, err := a.ToDomain(l); err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping action of scenario %s", err, s.Name))
+		} else {
+			actions = append(actions, *mapped)
+		}
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].At < actions[j].At
+	})
+	return scenario.Scenario{
+		Name:    s.Name,
+		Actions: actions,
+	}
+}
+
+func (a *ScenarioActionModel) ToDomain(l *zap.Logger) (*scenario.Action, error) {
+	if a.At < 0 {
+		return nil, fmt.Errorf("action %s scheduled before the scenario start", a.Action)
+	}
+	t, err := mapScenarioActionType(a.Action)
+	if err != nil {
+		return nil, err
+	}
+	action := scenario.Action{
+		At:         a.At,
+		ActionType: t,
+	}
+
+	if t != scenario.ChangeSpeed {
+		if a.NodeId == nil {
+			return nil, fmt.Errorf("action %s has no node", a.Action)
+		}
+		id, err := uuid.Parse(*a.NodeId)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid node id", *a.NodeId)
+		}
+		action.NodeId = id
+	}
+
+	switch t {
+	case scenario.SwitchWaveform:
+		if a.Waveform == nil {
+			return nil, fmt.Errorf("action %s has no waveform", a.Action)
+		}
+		w := a.Waveform.ToDomain(l)
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid waveform for action %s: %w", a.Action, err)
+		}
+		action.Waveform = &w
+	case scenario.InjectFault:
+		if a.Fault == nil {
+			return nil, fmt.Errorf("action %s has no fault", a.Action)
+		}
+		f, err := a.Fault.ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		action.Fault = f
+	case scenario.ChangeSpeed:
+		if a.Speed == nil || *a.Speed <= 0 {
+			return nil, fmt.Errorf("action %s needs a positive speed", a.Action)
+		}
+		action.Speed = *a.Speed
+	}
+	return &action, nil
+}
+
+func mapScenarioActionType(t string) (scenario.ActionType, error) {
+	switch strings.ToLower(t) {
+	case "switchwaveform":
+		return scenario.SwitchWaveform, nil
+	case "injectfault":
+		return scenario.InjectFault, nil
+	case "clearfaults":
+		return scenario.ClearFaults, nil
+	case "setspeed":
+		return scenario.ChangeSpeed, nil
+	case "enable":
+		return scenario.EnableNodes, nil
+	case "disable":
+		return scenario.DisableNodes, nil
+	default:
+		return 0, fmt.Errorf("unrecognized scenario action %s", t)
+	}
+}
@@ -0,0 +1,106 @@
+package serialization
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/scenario"
+	"go.uber.org/zap"
+)
+
+func TestScenarioActionModel_ToDomain(t *testing.T) {
+	const nodeId = `"nodeId": "27d05df1-e275-4aeb-bd1b-532151a7b3c7"`
+	for _, tc := range []struct {
+		name   string
+		action string
+		err    string
+		check  func(*testing.T, scenario.Action)
+	}{
+		{
+			name:   "switch waveform",
+			action: `{"at": 500, "action": "switchWaveform", ` + nodeId + `, "waveform": {"duration": 1000, "tickFrequency": 100, "type": "transitions"}}`,
+			check: func(t *testing.T, a scenario.Action) {
+				if a.ActionType != scenario.SwitchWaveform || a.At != 500 || a.Waveform == nil || a.Waveform.TickFrequency != 100 {
+					t.Errorf("unexpected action %+v", a)
+				}
+			},
+		},
+		{
+			name:   "switch to waveform without tick frequency",
+			action: `{"at": 500, "action": "switchWaveform", ` + nodeId + `, "waveform": {"duration": 1000, "type": "transitions"}}`,
+			err:    "no tick frequency",
+		},
+		{
+			name:   "switch without waveform",
+			action: `{"action": "switchWaveform", ` + nodeId + `}`,
+			err:    "has no waveform",
+		},
+		{
+			name:   "inject fault",
+			action: `{"action": "injectFault", ` + nodeId + `, "fault": {"type": "stuck", "value": 3}}`,
+			check: func(t *testing.T, a scenario.Action) {
+				if a.ActionType != scenario.InjectFault || a.Fault == nil || a.Fault.Value != 3 {
+					t.Errorf("unexpected action %+v", a)
+				}
+			},
+		},
+		{
+			name:   "set speed",
+			action: `{"action": "setSpeed", "speed": 2.5}`,
+			check: func(t *testing.T, a scenario.Action) {
+				if a.ActionType != scenario.ChangeSpeed || a.Speed != 2.5 {
+					t.Errorf("unexpected action %+v", a)
+				}
+			},
+		},
+		{name: "speed not positive", action: `{"action": "setSpeed", "speed": 0}`, err: "positive speed"},
+		{name: "before the start", action: `{"at": -1, "action": "disable", ` + nodeId + `}`, err: "before the scenario start"},
+		{name: "missing node", action: `{"action": "disable"}`, err: "has no node"},
+		{name: "invalid node id", action: `{"action": "enable", "nodeId": "pump"}`, err: "not a valid node id"},
+		{name: "unknown action", action: `{"action": "explode", ` + nodeId + `}`, err: "unrecognized scenario action"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m ScenarioActionModel
+			if err := json.Unmarshal([]byte(tc.action), &m); err != nil {
+				t.Fatal(err)
+			}
+			a, err := m.ToDomain(zap.NewNop())
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			tc.check(t, *a)
+		})
+	}
+}
+
+func TestMapScenarios_SkipsInvalidScenariosAndSortsActions(t *testing.T) {
+	var m []ScenarioModel
+	if err := json.Unmarshal([]byte(`[
+		{"name": "Startup", "actions": [
+			{"at": 900, "action": "setSpeed", "speed": 2},
+			{"at": 100, "action": "setSpeed", "speed": 4},
+			{"at": 500, "action": "setSpeed"}
+		]},
+		{"name": "", "actions": []},
+		{"name": "Startup", "actions": []}
+	]`), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := mapScenarios(m, zap.NewNop())
+
+	if len(scenarios) != 1 {
+		t.Fatalf("expected 1 scenario, got %d", len(scenarios))
+	}
+	actions := scenarios[0].Actions
+	if len(actions) != 2 || actions[0].At != 100 || actions[1].At != 900 {
+		t.Errorf("expected the 2 valid actions ordered by time, got %+v", actions)
+	}
+}
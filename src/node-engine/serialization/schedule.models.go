@@ -0,0 +1,140 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type ScheduleEntryModel struct {
+	Time  string  `json:"time"`
+	Value float64 `json:"value"`
+}
+
+type ScheduleHolidayModel struct {
+	Date    string               `json:"date"`
+	Entries []ScheduleEntryModel `json:"entries"`
+}
+
+var weekdayGroups = map[string][]time.Weekday{
+	"everyday": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"sunday":    time.Sunday,
+}
+
+func mapScheduleMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	valueType := waveform.DoubleValue
+	if m.ValueType != nil {
+		switch strings.ToLower(*m.ValueType) {
+		case "boolean":
+			valueType = waveform.BooleanValue
+		case "double":
+			valueType = waveform.DoubleValue
+		default:
+			l.Warn(fmt.Sprintf("unrecognized schedule value type %s, defaulting to double", *m.ValueType))
+		}
+	}
+
+	loc := time.Local
+	if m.Timezone != nil && *m.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(*m.Timezone); err != nil {
+			l.Warn(fmt.Sprintf("unrecognized timezone %s, defaulting to local time", *m.Timezone))
+			loc = time.Local
+		}
+	}
+
+	defaultValue := 0.0
+	if m.Default != nil {
+		defaultValue = *m.Default
+	}
+
+	// groups are applied first so that explicitly named days take precedence
+	days := make(map[time.Weekday][]waveform.ScheduleEntry)
+	for _, g := range []string{"everyday", "weekdays", "weekend"} {
+		if e, found := m.Days[g]; found {
+			for _, d := range weekdayGroups[g] {
+				days[d] = mapScheduleEntries(e, valueType, l)
+			}
+		}
+	}
+	for k, e := range m.Days {
+		if d, found := weekdayNames[strings.ToLower(k)]; found {
+			days[d] = mapScheduleEntries(e, valueType, l)
+		} else if _, found := weekdayGroups[strings.ToLower(k)]; !found {
+			l.Warn(fmt.Sprintf("unrecognized schedule day %s, skipping", k))
+		}
+	}
+
+	holidays := make([]waveform.ScheduleHoliday, 0, len(m.Holidays))
+	for _, h := range m.Holidays {
+		d, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			l.Warn(fmt.Sprintf("invalid holiday date %s, skipping", h.Date))
+			continue
+		}
+		holidays = append(holidays, waveform.ScheduleHoliday{
+			Year:    d.Year(),
+			Month:   d.Month(),
+			Day:     d.Day(),
+			Entries: mapScheduleEntries(h.Entries, valueType, l),
+		})
+	}
+
+	var meta waveform.WaveformMeta = waveform.ScheduleWaveformMeta{
+		Location:  loc,
+		ValueType: valueType,
+		Default:   mapScheduleValue(defaultValue, valueType),
+		Days:      days,
+		Holidays:  holidays,
+	}
+	return &meta
+}
+
+func mapScheduleEntries(m []ScheduleEntryModel, t waveform.ValueType, l *zap.Logger) []waveform.ScheduleEntry {
+	entries := make([]waveform.ScheduleEntry, 0, len(m))
+	for _, e := range m {
+		timeOfDay, err := parseTimeOfDay(e.Time)
+		if err != nil {
+			l.Warn(fmt.Sprintf("invalid schedule time %s, skipping entry", e.Time))
+			continue
+		}
+		entries = append(entries, waveform.ScheduleEntry{
+			TimeOfDay: timeOfDay,
+			Value:     mapScheduleValue(e.Value, t),
+		})
+	}
+	return entries
+}
+
+func mapScheduleValue(v float64, t waveform.ValueType) waveformvalue.WaveformPointValue {
+	if t == waveform.BooleanValue {
+		return &waveformvalue.Transition{Value: v != 0}
+	}
+	return &waveformvalue.DoubleValue{Value: v}
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %s", s)
+}
@@ -0,0 +1,61 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"go.uber.org/zap"
+)
+
+type TankModel struct {
+	Area        float64  `json:"area"`
+	Height      float64  `json:"height"`
+	Level       float64  `json:"level"`
+	Inflow      *string  `json:"inflow,omitempty"`
+	Outflow     *string  `json:"outflow,omitempty"`
+	ValveFlow   float64  `json:"valveFlow"`
+	PumpFlow    float64  `json:"pumpFlow"`
+	ValveOpen   bool     `json:"valveOpen"`
+	PumpRunning bool     `json:"pumpRunning"`
+	HighLevel   *float64 `json:"highLevel,omitempty"`
+	LowLevel    *float64 `json:"lowLevel,omitempty"`
+}
+
+func mapTank(p json.RawMessage, l *zap.Logger) template.Template {
+	m := TankModel{
+		Area:      1,
+		Height:    2,
+		ValveFlow: 10,
+		PumpFlow:  10,
+	}
+	if !unmarshalParameters(p, &m, l) {
+		return nil
+	}
+	if m.Area <= 0 || m.Height <= 0 {
+		l.Warn(fmt.Sprintf("invalid tank dimensions, area %f & height %f", m.Area, m.Height))
+		return nil
+	}
+
+	// switches default to 90% & 10% of the height
+	t := template.TankTemplate{
+		Area:        m.Area,
+		Height:      m.Height,
+		Level:       m.Level,
+		Inflow:      mapNodeReference(m.Inflow, l),
+		Outflow:     mapNodeReference(m.Outflow, l),
+		ValveFlow:   m.ValveFlow,
+		PumpFlow:    m.PumpFlow,
+		ValveOpen:   m.ValveOpen,
+		PumpRunning: m.PumpRunning,
+		HighLevel:   m.Height * 0.9,
+		LowLevel:    m.Height * 0.1,
+	}
+	if m.HighLevel != nil {
+		t.HighLevel = *m.HighLevel
+	}
+	if m.LowLevel != nil {
+		t.LowLevel = *m.LowLevel
+	}
+	return t
+}
@@ -0,0 +1,61 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TemplateModel struct {
+	TemplateType  string          `json:"type"`
+	TickFrequency int32           `json:"tickFrequency"`
+	Parameters    json.RawMessage `json:"parameters,omitempty"`
+}
+
+func (m *TemplateModel) ToDomain(id uuid.UUID, label string, l *zap.Logger) opcnode.OpcStructureNode {
+	if m == nil {
+		l.Warn(fmt.Sprintf("missing template definition for %s, skipping node", label))
+		return nil
+	}
+	if m.TickFrequency <= 0 {
+		l.Warn(fmt.Sprintf("invalid tick frequency %d for %s, skipping node", m.TickFrequency, label))
+		return nil
+	}
+
+	var t template.Template
+	switch m.TemplateType {
+	case "controlLoop":
+		t = mapControlLoop(m.Parameters, l)
+	case "tank":
+		t = mapTank(m.Parameters, l)
+	case "motor":
+		t = mapMotor(m.Parameters, l)
+	case "packml":
+		t = mapPackML(m.Parameters, l)
+	case "productionLine":
+		t = mapProductionLine(m.Parameters, l)
+	default:
+		l.Warn(fmt.Sprintf("unrecognized template %s, skipping node", m.TemplateType))
+		return nil
+	}
+	if t == nil {
+		return nil
+	}
+	return opcnode.CreateTemplateNode(id, label, m.TickFrequency, t)
+}
+
+// unmarshalParameters fills the defaults of a template with the given parameters
+func unmarshalParameters(p json.RawMessage, v any, l *zap.Logger) bool {
+	if len(p) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(p, v); err != nil {
+		l.Warn(fmt.Sprintf("invalid template parameters: %v", err))
+		return false
+	}
+	return true
+}
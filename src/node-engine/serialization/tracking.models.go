@@ -0,0 +1,54 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"go.uber.org/zap"
+)
+
+func mapTrackingMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	source := mapNodeReference(m.Source, l)
+	if source == nil {
+		l.Warn("missing tracking source")
+		return nil
+	}
+
+	meta := waveform.TrackingWaveformMeta{
+		Source:       *source,
+		Mode:         waveform.FirstOrderLag,
+		TimeConstant: 1000,
+		Damping:      0.5,
+	}
+	if m.Mode != nil {
+		switch strings.ToLower(*m.Mode) {
+		case "ramp":
+			meta.Mode = waveform.Ramp
+		case "lag":
+			meta.Mode = waveform.FirstOrderLag
+		case "overshoot":
+			meta.Mode = waveform.Overshoot
+		default:
+			l.Warn(fmt.Sprintf("unrecognized tracking mode %s, defaulting to lag", *m.Mode))
+		}
+	}
+	if m.Rate != nil {
+		meta.Rate = *m.Rate
+	}
+	if meta.Mode == waveform.Ramp && meta.Rate <= 0 {
+		l.Warn("missing ramp rate, node will not follow its source")
+	}
+	if m.TimeConstant != nil {
+		meta.TimeConstant = *m.TimeConstant
+	}
+	if m.Damping != nil {
+		meta.Damping = *m.Damping
+	}
+	if m.Initial != nil {
+		meta.Initial = *m.Initial
+	}
+
+	var d waveform.WaveformMeta = meta
+	return &d
+}
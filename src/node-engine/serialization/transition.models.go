@@ -0,0 +1,44 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"go.uber.org/zap"
+)
+
+func mapTransitionMeta(m *WaveformMetaModel, l *zap.Logger) *waveform.WaveformMeta {
+	mode := strings.ToLower(*m.Mode)
+	if mode == "scheduled" {
+		return nil
+	}
+	if mode != "poisson" {
+		l.Warn(fmt.Sprintf("unrecognized transition mode %s, using the transition points", *m.Mode))
+		return nil
+	}
+
+	meta := waveform.TransitionWaveformMeta{
+		Mode: waveform.PoissonPulses,
+		Seed: m.Seed,
+	}
+	if m.Rate != nil {
+		meta.Rate = *m.Rate
+	}
+	if meta.Rate <= 0 {
+		l.Warn("missing pulse rate, no pulses will be emitted")
+	}
+	if m.PulseWidth != nil {
+		meta.PulseWidth = *m.PulseWidth
+	}
+	if m.Burstiness != nil {
+		if *m.Burstiness < 0 || *m.Burstiness >= 1 {
+			l.Warn(fmt.Sprintf("burstiness %f out of range, ignoring it", *m.Burstiness))
+		} else {
+			meta.Burstiness = *m.Burstiness
+		}
+	}
+
+	var d waveform.WaveformMeta = meta
+	return &d
+}
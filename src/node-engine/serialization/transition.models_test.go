@@ -0,0 +1,60 @@
+package serialization
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	"go.uber.org/zap"
+)
+
+func TestMapTransitionMeta(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		meta string
+		want *waveform.TransitionWaveformMeta
+	}{
+		{"scheduled", `{"mode": "scheduled"}`, nil},
+		{"unknown mode", `{"mode": "random"}`, nil},
+		{
+			"poisson pulses",
+			`{"mode": "Poisson", "rate": 6, "pulseWidth": 250, "burstiness": 0.3, "seed": 42}`,
+			&waveform.TransitionWaveformMeta{Mode: waveform.PoissonPulses, Rate: 6, PulseWidth: 250, Burstiness: 0.3, Seed: ptr(int64(42))},
+		},
+		{
+			"without rate",
+			`{"mode": "poisson", "pulseWidth": 250}`,
+			&waveform.TransitionWaveformMeta{Mode: waveform.PoissonPulses, PulseWidth: 250},
+		},
+		{
+			"burstiness out of range",
+			`{"mode": "poisson", "rate": 6, "burstiness": 1}`,
+			&waveform.TransitionWaveformMeta{Mode: waveform.PoissonPulses, Rate: 6},
+		},
+		{
+			"negative burstiness",
+			`{"mode": "poisson", "rate": 6, "burstiness": -0.5}`,
+			&waveform.TransitionWaveformMeta{Mode: waveform.PoissonPulses, Rate: 6},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m WaveformMetaModel
+			if err := json.Unmarshal([]byte(tc.meta), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			meta := mapTransitionMeta(&m, zap.NewNop())
+
+			if tc.want == nil {
+				if meta != nil {
+					t.Errorf("expected the transition points to be used, got %+v", *meta)
+				}
+				return
+			}
+			if meta == nil || !reflect.DeepEqual(*meta, waveform.WaveformMeta(*tc.want)) {
+				t.Errorf("expected %+v, got %+v", *tc.want, meta)
+			}
+		})
+	}
+}
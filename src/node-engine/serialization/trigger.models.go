@@ -0,0 +1,129 @@
+package serialization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TriggerModel struct {
+	Source    string               `json:"source"`
+	When      string               `json:"when"`
+	Threshold float64              `json:"threshold"`
+	Then      []TriggerActionModel `json:"then"`
+}
+
+type TriggerActionModel struct {
+	Action   string   `json:"action"`
+	NodeId   *string  `json:"nodeId,omitempty"`
+	Value    *float64 `json:"value,omitempty"`
+	Scenario *string  `json:"scenario,omitempty"`
+}
+
+func mapTriggers(m []TriggerModel, l *zap.Logger) []trigger.Trigger {
+	triggers := make([]trigger.Trigger, 0, len(m))
+	for _, t := range m {
+		if mapped, err := t.ToDomain(l); err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping trigger", err))
+		} else {
+			triggers = append(triggers, *mapped)
+		}
+	}
+	return triggers
+}
+
+func (t *TriggerModel) ToDomain(l *zap.Logger) (*trigger.Trigger, error) {
+	source, err := uuid.Parse(t.Source)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid source node id", t.Source)
+	}
+	condition, err := mapTriggerCondition(t.When)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]trigger.Action, 0, len(t.Then))
+	for _, a := range t.Then {
+		if mapped, err := a.ToDomain(); err != nil {
+			l.Warn(fmt.Sprintf("%v, skipping action of trigger on %s", err, t.Source))
+		} else {
+			actions = append(actions, *mapped)
+		}
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("trigger on %s has no actions", t.Source)
+	}
+	return &trigger.Trigger{
+		Source:    source,
+		Condition: condition,
+		Threshold: t.Threshold,
+		Actions:   actions,
+	}, nil
+}
+
+func (a *TriggerActionModel) ToDomain() (*trigger.Action, error) {
+	t, err := mapTriggerActionType(a.Action)
+	if err != nil {
+		return nil, err
+	}
+	action := trigger.Action{ActionType: t}
+
+	if t == trigger.StartScenario {
+		if a.Scenario == nil || *a.Scenario == "" {
+			return nil, fmt.Errorf("action %s has no scenario", a.Action)
+		}
+		action.Scenario = *a.Scenario
+		return &action, nil
+	}
+
+	if a.NodeId == nil {
+		return nil, fmt.Errorf("action %s has no node", a.Action)
+	}
+	id, err := uuid.Parse(*a.NodeId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", *a.NodeId)
+	}
+	action.NodeId = id
+	if t == trigger.SetValue {
+		if a.Value == nil {
+			return nil, fmt.Errorf("action %s has no value", a.Action)
+		}
+		action.Value = *a.Value
+	}
+	return &action, nil
+}
+
+func mapTriggerCondition(c string) (trigger.ConditionType, error) {
+	switch strings.ToLower(c) {
+	case "risesabove":
+		return trigger.RisesAbove, nil
+	case "fallsbelow":
+		return trigger.FallsBelow, nil
+	case "becomestrue":
+		return trigger.BecomesTrue, nil
+	case "becomesfalse":
+		return trigger.BecomesFalse, nil
+	default:
+		return 0, fmt.Errorf("unrecognized trigger condition %s", c)
+	}
+}
+
+func mapTriggerActionType(t string) (trigger.ActionType, error) {
+	switch strings.ToLower(t) {
+	case "start":
+		return trigger.StartWaveform, nil
+	case "stop":
+		return trigger.StopWaveform, nil
+	case "restart":
+		return trigger.RestartWaveform, nil
+	case "set":
+		return trigger.SetValue, nil
+	case "startscenario":
+		return trigger.StartScenario, nil
+	default:
+		return 0, fmt.Errorf("unrecognized trigger action %s", t)
+	}
+}
@@ -0,0 +1,94 @@
+package serialization
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestTriggerModel_ToDomain(t *testing.T) {
+	const (
+		sourceId = "27d05df1-e275-4aeb-bd1b-532151a7b3c7"
+		targetId = "5f6a7b8c-9d0e-4f1a-8b3c-4d5e6f7a8b9c"
+	)
+	for _, tc := range []struct {
+		name    string
+		trigger string
+		err     string
+		want    *trigger.Trigger
+	}{
+		{
+			"all actions",
+			`{"source": "` + sourceId + `", "when": "risesAbove", "threshold": 80, "then": [
+				{"action": "Stop", "nodeId": "` + targetId + `"},
+				{"action": "set", "nodeId": "` + targetId + `", "value": 5},
+				{"action": "startScenario", "scenario": "Shutdown"}
+			]}`,
+			"",
+			&trigger.Trigger{
+				Source:    uuid.MustParse(sourceId),
+				Condition: trigger.RisesAbove,
+				Threshold: 80,
+				Actions: []trigger.Action{
+					{ActionType: trigger.StopWaveform, NodeId: uuid.MustParse(targetId)},
+					{ActionType: trigger.SetValue, NodeId: uuid.MustParse(targetId), Value: 5},
+					{ActionType: trigger.StartScenario, Scenario: "Shutdown"},
+				},
+			},
+		},
+		{
+			"invalid actions skipped",
+			`{"source": "` + sourceId + `", "when": "becomesFalse", "then": [
+				{"action": "set", "nodeId": "` + targetId + `"},
+				{"action": "start"},
+				{"action": "restart", "nodeId": "pump"},
+				{"action": "startScenario"},
+				{"action": "explode", "nodeId": "` + targetId + `"},
+				{"action": "restart", "nodeId": "` + targetId + `"}
+			]}`,
+			"",
+			&trigger.Trigger{
+				Source:    uuid.MustParse(sourceId),
+				Condition: trigger.BecomesFalse,
+				Actions:   []trigger.Action{{ActionType: trigger.RestartWaveform, NodeId: uuid.MustParse(targetId)}},
+			},
+		},
+		{"invalid source", `{"source": "pump", "when": "becomesTrue", "then": [{"action": "start", "nodeId": "` + targetId + `"}]}`, "not a valid source node id", nil},
+		{"unknown condition", `{"source": "` + sourceId + `", "when": "changes", "then": [{"action": "start", "nodeId": "` + targetId + `"}]}`, "unrecognized trigger condition", nil},
+		{"no valid actions", `{"source": "` + sourceId + `", "when": "fallsBelow", "then": [{"action": "start"}]}`, "has no actions", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m TriggerModel
+			if err := json.Unmarshal([]byte(tc.trigger), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			tr, err := m.ToDomain(zap.NewNop())
+
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("expected an error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tr.Source != tc.want.Source || tr.Condition != tc.want.Condition || tr.Threshold != tc.want.Threshold {
+				t.Errorf("expected %+v, got %+v", *tc.want, *tr)
+			}
+			if len(tr.Actions) != len(tc.want.Actions) {
+				t.Fatalf("expected actions %+v, got %+v", tc.want.Actions, tr.Actions)
+			}
+			for i, a := range tc.want.Actions {
+				if tr.Actions[i] != a {
+					t.Errorf("expected action %+v, got %+v", a, tr.Actions[i])
+				}
+			}
+		})
+	}
+}
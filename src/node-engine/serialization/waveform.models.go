@@ -3,9 +3,11 @@ package serialization
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -15,6 +17,7 @@ type WaveformModel struct {
 	WaveformType     string               `json:"type"`
 	TransitionPoints []WaveformValueModel `json:"transitionPoints"`
 	Meta             *WaveformMetaModel   `json:"meta"`
+	Alignment        *AlignmentModel      `json:"alignment,omitempty"`
 }
 
 type WaveformValueModel struct {
@@ -23,17 +26,59 @@ type WaveformValueModel struct {
 }
 
 type WaveformMetaModel struct {
-	Smoothing *string `json:"smoothing"`
+	Smoothing *string                         `json:"smoothing"`
+	ValueType *string                         `json:"valueType,omitempty"`
+	Timezone  *string                         `json:"timezone,omitempty"`
+	Default   *float64                        `json:"default,omitempty"`
+	Days      map[string][]ScheduleEntryModel `json:"days,omitempty"`
+	Holidays  []ScheduleHolidayModel          `json:"holidays,omitempty"`
+
+	Source       *string  `json:"source,omitempty"`
+	Mode         *string  `json:"mode,omitempty"`
+	Rate         *float64 `json:"rate,omitempty"`
+	TimeConstant *int64   `json:"timeConstant,omitempty"`
+	Damping      *float64 `json:"damping,omitempty"`
+	Initial      *float64 `json:"initial,omitempty"`
+
+	Filter *string  `json:"filter,omitempty"`
+	Delay  *int64   `json:"delay,omitempty"`
+	Window *int64   `json:"window,omitempty"`
+	Alpha  *float64 `json:"alpha,omitempty"`
+
+	Scale    *float64 `json:"scale,omitempty"`
+	ResetAt  *float64 `json:"resetAt,omitempty"`
+	Rollover *bool    `json:"rollover,omitempty"`
+	Reset    *string  `json:"reset,omitempty"`
+	Edge     *string  `json:"edge,omitempty"`
+
+	States      []MarkovStateModel `json:"states,omitempty"`
+	Transitions [][]float64        `json:"transitions,omitempty"`
+	Seed        *int64             `json:"seed,omitempty"`
+
+	PulseWidth *int64   `json:"pulseWidth,omitempty"`
+	Burstiness *float64 `json:"burstiness,omitempty"`
+}
+
+type AlignmentModel struct {
+	Anchor   string  `json:"anchor"`
+	Timezone *string `json:"timezone"`
 }
 
 func (w *WaveformModel) ToDomain(l *zap.Logger) waveform.Waveform {
 	waveformType := mapWaveformType(w.WaveformType, l.Named("mapper"))
+	meta := mapWaveformMeta(w.Meta, waveformType, l)
+	duration := w.Duration
+	if !isCyclic(waveformType, meta) && duration < int64(w.TickFrequency) {
+		// these waveforms do not loop, a single tick cycle is enough
+		duration = int64(w.TickFrequency)
+	}
 	return waveform.Waveform{
-		Duration:         w.Duration,
+		Duration:         duration,
 		TickFrequency:    w.TickFrequency,
 		WaveformType:     waveformType,
 		TransitionPoints: mapWaveformValues(w.TransitionPoints, waveformType),
-		Meta:             mapWaveformMeta(w.Meta, waveformType, l),
+		Meta:             meta,
+		Alignment:        mapAlignment(w.Alignment, l),
 	}
 }
 
@@ -43,6 +88,18 @@ func mapWaveformType(t string, l *zap.Logger) waveform.WaveformType {
 		return waveform.NumericValues
 	case "transitions":
 		return waveform.Transitions
+	case "schedule":
+		return waveform.Schedule
+	case "tracking":
+		return waveform.Tracking
+	case "filter":
+		return waveform.Filter
+	case "totalizer":
+		return waveform.Totalizer
+	case "counter":
+		return waveform.Counter
+	case "markov":
+		return waveform.Markov
 	default:
 		l.Warn(fmt.Sprintf("unrecognized waveform type %s, defaulting to transitions", t))
 		return waveform.Transitions
@@ -70,7 +127,10 @@ func mapWaveformMeta(m *WaveformMetaModel, t waveform.WaveformType, l *zap.Logge
 
 	switch t {
 	case waveform.Transitions:
-		return nil
+		if m == nil || m.Mode == nil {
+			return nil
+		}
+		return mapTransitionMeta(m, l)
 	case waveform.NumericValues:
 		if m == nil {
 			l.Warn(fmt.Sprintf("missing meta, using defaults for type %v", t))
@@ -96,6 +156,95 @@ func mapWaveformMeta(m *WaveformMetaModel, t waveform.WaveformType, l *zap.Logge
 			Smoothing: s,
 		}
 		return &m
+	case waveform.Schedule:
+		if m == nil {
+			l.Warn("missing schedule definition")
+			return nil
+		}
+		return mapScheduleMeta(m, l)
+	case waveform.Tracking:
+		if m == nil {
+			l.Warn("missing tracking definition")
+			return nil
+		}
+		return mapTrackingMeta(m, l)
+	case waveform.Filter:
+		if m == nil {
+			l.Warn("missing filter definition")
+			return nil
+		}
+		return mapFilterMeta(m, l)
+	case waveform.Totalizer:
+		if m == nil {
+			l.Warn("missing totalizer definition")
+			return nil
+		}
+		return mapTotalizerMeta(m, l)
+	case waveform.Counter:
+		if m == nil {
+			l.Warn("missing counter definition")
+			return nil
+		}
+		return mapCounterMeta(m, l)
+	case waveform.Markov:
+		if m == nil {
+			l.Warn("missing markov chain definition")
+			return nil
+		}
+		return mapMarkovMeta(m, l)
 	}
 	return nil
 }
+
+func isCyclic(t waveform.WaveformType, m *waveform.WaveformMeta) bool {
+	if m != nil {
+		if meta, ok := (*m).(waveform.TransitionWaveformMeta); ok && meta.Mode == waveform.PoissonPulses {
+			return false
+		}
+	}
+	return t == waveform.Transitions || t == waveform.NumericValues
+}
+
+func mapAlignment(m *AlignmentModel, l *zap.Logger) *waveform.WaveformAlignment {
+	if m == nil {
+		return nil
+	}
+
+	loc := time.Local
+	if m.Timezone != nil && *m.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(*m.Timezone); err != nil {
+			l.Warn(fmt.Sprintf("unrecognized timezone %s, ignoring alignment", *m.Timezone))
+			return nil
+		}
+	}
+
+	// the anchor is either a full local date & time or only a time of day,
+	// in the latter case any date works since cycles repeat anyway
+	layouts := []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "15:04:05", "15:04"}
+	for _, layout := range layouts {
+		if a, err := time.ParseInLocation(layout, m.Anchor, loc); err == nil {
+			if a.Year() == 0 {
+				a = time.Date(2000, time.January, 1, a.Hour(), a.Minute(), a.Second(), 0, loc)
+			}
+			return &waveform.WaveformAlignment{
+				Anchor: a,
+			}
+		}
+	}
+
+	l.Warn(fmt.Sprintf("invalid alignment anchor %s, ignoring alignment", m.Anchor))
+	return nil
+}
+
+func mapNodeReference(id *string, l *zap.Logger) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	r, err := uuid.Parse(*id)
+	if err != nil {
+		l.Warn(fmt.Sprintf("invalid node reference %s", *id))
+		return nil
+	}
+	return &r
+}
@@ -0,0 +1,53 @@
+package serialization
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMapAlignment(t *testing.T) {
+	bucharest, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name      string
+		alignment string
+		anchor    *time.Time
+	}{
+		{"no alignment", `null`, nil},
+		{"date & time", `{"anchor": "2024-03-01T06:30:00", "timezone": "Europe/Bucharest"}`, ptr(time.Date(2024, time.March, 1, 6, 30, 0, 0, bucharest))},
+		{"date & time separated by a space", `{"anchor": "2024-03-01 06:30:00", "timezone": "UTC"}`, ptr(time.Date(2024, time.March, 1, 6, 30, 0, 0, time.UTC))},
+		{"time of day", `{"anchor": "06:30:15", "timezone": "UTC"}`, ptr(time.Date(2000, time.January, 1, 6, 30, 15, 0, time.UTC))},
+		{"time of day without seconds", `{"anchor": "06:30", "timezone": "Europe/Bucharest"}`, ptr(time.Date(2000, time.January, 1, 6, 30, 0, 0, bucharest))},
+		{"local time", `{"anchor": "06:30"}`, ptr(time.Date(2000, time.January, 1, 6, 30, 0, 0, time.Local))},
+		{"unknown timezone", `{"anchor": "06:30", "timezone": "Mars/Olympus"}`, nil},
+		{"invalid anchor", `{"anchor": "half past six", "timezone": "UTC"}`, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m *AlignmentModel
+			if err := json.Unmarshal([]byte(tc.alignment), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			a := mapAlignment(m, zap.NewNop())
+
+			if tc.anchor == nil {
+				if a != nil {
+					t.Errorf("expected no alignment, got %v", a.Anchor)
+				}
+				return
+			}
+			if a == nil || !a.Anchor.Equal(*tc.anchor) || a.Anchor.Location().String() != tc.anchor.Location().String() {
+				t.Errorf("expected anchor %v, got %+v", *tc.anchor, a)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,19 @@
+package simulationclock
+
+import "time"
+
+// SimulationClock tells the simulated time, which runs
+// faster or slower than the wall clock by the speed factor
+type SimulationClock interface {
+	init()
+	Now() time.Time
+	Until(time.Time) time.Duration
+	GetSpeed() float64
+	SetSpeed(float64) error
+}
+
+func CreateNew() SimulationClock {
+	c := simulationClockImpl{}
+	c.init()
+	return &c
+}
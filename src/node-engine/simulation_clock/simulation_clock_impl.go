@@ -0,0 +1,60 @@
+package simulationclock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type simulationClockImpl struct {
+	lock       sync.RWMutex
+	realAnchor time.Time
+	simAnchor  time.Time
+	speed      float64
+}
+
+func (c *simulationClockImpl) init() {
+	now := time.Now()
+	c.realAnchor = now
+	c.simAnchor = now
+	c.speed = 1
+}
+
+func (c *simulationClockImpl) Now() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.toSimulated(time.Now())
+}
+
+// Until returns the wall-clock time left until the simulated time t
+func (c *simulationClockImpl) Until(t time.Time) time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	real := c.realAnchor.Add(time.Duration(float64(t.Sub(c.simAnchor)) / c.speed))
+	return time.Until(real)
+}
+
+func (c *simulationClockImpl) GetSpeed() float64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.speed
+}
+
+func (c *simulationClockImpl) SetSpeed(f float64) error {
+	if f <= 0 {
+		return fmt.Errorf("invalid speed %f, must be positive", f)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// the simulated time continues from where it is at, only its pace changes
+	now := time.Now()
+	c.simAnchor = c.toSimulated(now)
+	c.realAnchor = now
+	c.speed = f
+	return nil
+}
+
+func (c *simulationClockImpl) toSimulated(t time.Time) time.Time {
+	return c.simAnchor.Add(time.Duration(float64(t.Sub(c.realAnchor)) * c.speed))
+}
@@ -0,0 +1,112 @@
+package templatesimulators
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"go.uber.org/zap"
+)
+
+type controlLoopSimulator struct {
+	logger   *zap.Logger
+	node     opcnode.OpcTemplateNode
+	template template.ControlLoopTemplate
+	reader   valuecomputers.NodeValueReader
+	random   *rand.Rand
+	setpoint float64
+	mode     template.ControlMode
+	output   float64
+	pv       float64
+	prevPv   float64
+	integral float64
+	pipeline []float64
+}
+
+func (s *controlLoopSimulator) Init() {
+	s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	s.setpoint = s.template.Setpoint
+	s.mode = s.template.Mode
+	s.output = s.clamp(s.template.Output)
+
+	// the process starts settled at the initial output
+	s.pv = s.template.ProcessGain * s.output
+	s.prevPv = s.pv
+	s.integral = s.output
+	s.pipeline = make([]float64, s.getTickCount(s.template.DeadTime)+1)
+	for i := range s.pipeline {
+		s.pipeline[i] = s.output
+	}
+}
+
+func (s *controlLoopSimulator) Step() map[string]waveformvalue.WaveformPointValue {
+	dt := float64(s.node.TickFrequency) / 1000
+	measured := s.pv
+	if s.template.Noise > 0 {
+		measured += s.random.NormFloat64() * s.template.Noise
+	}
+
+	// apply client writes, the output can only be written in manual mode
+	if v, ok := takeWrite(s.reader, s.node, template.SetpointSignal); ok {
+		s.setpoint = v
+	}
+	if v, ok := takeWrite(s.reader, s.node, template.ModeSignal); ok {
+		mode := template.Manual
+		if v != 0 {
+			mode = template.Auto
+		}
+		if mode == template.Auto && s.mode == template.Manual {
+			// bumpless transfer, the controller continues from the current output
+			s.integral = s.output - s.template.Kp*(s.setpoint-measured)
+			s.prevPv = measured
+		}
+		s.mode = mode
+	}
+	if v, ok := takeWrite(s.reader, s.node, template.OutputSignal); ok && s.mode == template.Manual {
+		s.output = s.clamp(v)
+	}
+
+	if s.mode == template.Auto {
+		e := s.setpoint - measured
+		// the derivative acts on the measurement to avoid kicks on setpoint changes
+		derivative := -s.template.Kd * (measured - s.prevPv) / dt
+		integral := s.integral + s.template.Ki*e*dt
+		output := s.template.Kp*e + integral + derivative
+		// the integral is frozen while the output is saturated
+		if output >= s.template.OutputMin && output <= s.template.OutputMax {
+			s.integral = integral
+		}
+		s.output = s.clamp(output)
+	}
+	s.prevPv = measured
+
+	// the process responds to the output as it was one dead time ago
+	s.pipeline = append(s.pipeline[1:], s.output)
+	input := s.template.ProcessGain * s.pipeline[0]
+	if s.template.Disturbance != nil {
+		if d, ok := readNumeric(s.reader, *s.template.Disturbance); ok {
+			input += d
+		}
+	}
+	timeConstant := float64(s.template.TimeConstant) / 1000
+	s.pv += (input - s.pv) * dt / (timeConstant + dt)
+
+	return map[string]waveformvalue.WaveformPointValue{
+		template.SetpointSignal:     &waveformvalue.DoubleValue{Value: s.setpoint},
+		template.ProcessValueSignal: &waveformvalue.DoubleValue{Value: measured},
+		template.OutputSignal:       &waveformvalue.DoubleValue{Value: s.output},
+		template.ModeSignal:         &waveformvalue.IntegerValue{Value: int32(s.mode)},
+	}
+}
+
+func (s *controlLoopSimulator) clamp(v float64) float64 {
+	return math.Max(s.template.OutputMin, math.Min(s.template.OutputMax, v))
+}
+
+func (s *controlLoopSimulator) getTickCount(d int64) int {
+	return int(d / int64(s.node.TickFrequency))
+}
@@ -0,0 +1,98 @@
+package templatesimulators
+
+import (
+	"math"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"go.uber.org/zap"
+)
+
+type motorSimulator struct {
+	logger      *zap.Logger
+	node        opcnode.OpcTemplateNode
+	template    template.MotorTemplate
+	reader      valuecomputers.NodeValueReader
+	command     bool
+	running     bool
+	commandAge  int64
+	speed       float64
+	temperature float64
+}
+
+func (s *motorSimulator) Init() {
+	// a running motor starts at rated speed & operating temperature
+	s.command = s.template.Running
+	s.running = s.template.Running
+	s.commandAge = s.template.FeedbackDelay
+	s.speed = 0
+	if s.running {
+		s.speed = s.template.RatedSpeed
+	}
+	s.temperature = s.getSteadyTemperature(s.getLoadCurrent())
+}
+
+func (s *motorSimulator) Step() map[string]waveformvalue.WaveformPointValue {
+	dt := float64(s.node.TickFrequency) / 1000
+	if v, ok := takeWrite(s.reader, s.node, template.CommandSignal); ok && (v != 0) != s.command {
+		s.command = v != 0
+		s.commandAge = 0
+	}
+
+	// the feedback follows the command after a delay
+	if s.commandAge >= s.template.FeedbackDelay {
+		s.running = s.command
+	}
+	s.commandAge += int64(s.node.TickFrequency)
+
+	current := 0.0
+	if s.command {
+		s.speed = math.Min(s.template.RatedSpeed, s.speed+s.getRamp(s.template.AccelerationTime, dt))
+		// the inrush current fades as the motor gets up to speed
+		fraction := math.Pow(s.speed/s.template.RatedSpeed, 2)
+		inrush := s.template.StartingCurrent * s.template.RatedCurrent
+		current = inrush*(1-fraction) + s.getLoadCurrent()*fraction
+	} else {
+		// the motor coasts down without drawing current
+		s.speed = math.Max(0, s.speed-s.getRamp(s.template.DecelerationTime, dt))
+	}
+
+	// the winding heats up with the square of the current
+	timeConstant := float64(s.template.ThermalTimeConstant) / 1000
+	s.temperature += (s.getSteadyTemperature(current) - s.temperature) * dt / (timeConstant + dt)
+
+	return map[string]waveformvalue.WaveformPointValue{
+		template.CommandSignal:     &waveformvalue.Transition{Value: s.command},
+		template.RunningSignal:     &waveformvalue.Transition{Value: s.running},
+		template.SpeedSignal:       &waveformvalue.DoubleValue{Value: s.speed},
+		template.CurrentSignal:     &waveformvalue.DoubleValue{Value: current},
+		template.TemperatureSignal: &waveformvalue.DoubleValue{Value: s.temperature},
+	}
+}
+
+func (s *motorSimulator) getRamp(d int64, dt float64) float64 {
+	if d <= 0 {
+		return s.template.RatedSpeed
+	}
+	return s.template.RatedSpeed * dt / (float64(d) / 1000)
+}
+
+func (s *motorSimulator) getLoadCurrent() float64 {
+	if !s.running && !s.command {
+		return 0
+	}
+	load := s.template.Load
+	if s.template.LoadSource != nil {
+		if v, ok := readNumeric(s.reader, *s.template.LoadSource); ok {
+			load = math.Max(0, v)
+		}
+	}
+	noLoad := s.template.NoLoadCurrent
+	return s.template.RatedCurrent * (noLoad + (1-noLoad)*load)
+}
+
+func (s *motorSimulator) getSteadyTemperature(current float64) float64 {
+	return s.template.AmbientTemperature + s.template.TemperatureRise*math.Pow(current/s.template.RatedCurrent, 2)
+}
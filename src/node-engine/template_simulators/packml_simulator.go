@@ -0,0 +1,112 @@
+package templatesimulators
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"go.uber.org/zap"
+)
+
+// acting states lead to these states once they are done
+var packMLCompletions = map[template.PackMLState]template.PackMLState{
+	template.Clearing:     template.Stopped,
+	template.Starting:     template.Execute,
+	template.Stopping:     template.Stopped,
+	template.Aborting:     template.Aborted,
+	template.Holding:      template.Held,
+	template.Unholding:    template.Execute,
+	template.Suspending:   template.Suspended,
+	template.Unsuspending: template.Execute,
+	template.Resetting:    template.Idle,
+	template.Completing:   template.Complete,
+}
+
+// commands lead from wait states to acting states, stop & abort are handled separately
+var packMLCommands = map[template.PackMLState]map[template.PackMLCommand]template.PackMLState{
+	template.Stopped:   {template.ResetCommand: template.Resetting},
+	template.Idle:      {template.StartCommand: template.Starting},
+	template.Execute:   {template.HoldCommand: template.Holding, template.SuspendCommand: template.Suspending},
+	template.Held:      {template.UnholdCommand: template.Unholding},
+	template.Suspended: {template.UnsuspendCommand: template.Unsuspending},
+	template.Complete:  {template.ResetCommand: template.Resetting},
+	template.Aborted:   {template.ClearCommand: template.Clearing},
+}
+
+type packMLSimulator struct {
+	logger   *zap.Logger
+	node     opcnode.OpcTemplateNode
+	template template.PackMLTemplate
+	reader   valuecomputers.NodeValueReader
+	random   *rand.Rand
+	state    template.PackMLState
+	elapsed  int64
+	fault    bool
+}
+
+func (s *packMLSimulator) Init() {
+	s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	s.state = s.template.InitialState
+	s.elapsed = 0
+	s.fault = false
+}
+
+func (s *packMLSimulator) Step() map[string]waveformvalue.WaveformPointValue {
+	dt := int64(s.node.TickFrequency)
+	if v, ok := takeWrite(s.reader, s.node, template.CommandSignal); ok {
+		s.handleCommand(template.PackMLCommand(v))
+	} else {
+		s.elapsed += dt
+		if next, found := packMLCompletions[s.state]; found && s.elapsed >= s.template.Durations[s.state] {
+			s.enter(next)
+		} else if s.state == template.Execute && s.template.ExecuteDuration > 0 && s.elapsed >= s.template.ExecuteDuration {
+			s.enter(template.Completing)
+		} else if s.state == template.Execute && s.random.Float64() < s.template.FaultRate*float64(dt)/3600000 {
+			s.logger.Info(fmt.Sprintf("random fault in %s, aborting", s.node.Label))
+			s.fault = true
+			s.enter(template.Aborting)
+		}
+	}
+
+	// commands are consumed, the command node is cleared once it is handled
+	return map[string]waveformvalue.WaveformPointValue{
+		template.StateSignal:   &waveformvalue.IntegerValue{Value: int32(s.state)},
+		template.CommandSignal: &waveformvalue.IntegerValue{Value: int32(template.NoCommand)},
+		template.FaultSignal:   &waveformvalue.Transition{Value: s.fault},
+	}
+}
+
+func (s *packMLSimulator) handleCommand(c template.PackMLCommand) {
+	switch {
+	case c == template.AbortCommand && s.state != template.Aborting && s.state != template.Aborted:
+		s.enter(template.Aborting)
+	case c == template.StopCommand && !s.isStoppedOrAborted():
+		s.enter(template.Stopping)
+	default:
+		if next, found := packMLCommands[s.state][c]; found {
+			if c == template.ClearCommand {
+				s.fault = false
+			}
+			s.enter(next)
+		} else {
+			s.logger.Debug(fmt.Sprintf("command %d not allowed in state %d, ignoring it", c, s.state))
+		}
+	}
+}
+
+func (s *packMLSimulator) isStoppedOrAborted() bool {
+	switch s.state {
+	case template.Stopped, template.Stopping, template.Aborting, template.Aborted, template.Clearing:
+		return true
+	}
+	return false
+}
+
+func (s *packMLSimulator) enter(state template.PackMLState) {
+	s.state = state
+	s.elapsed = 0
+}
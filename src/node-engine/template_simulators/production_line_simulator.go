@@ -0,0 +1,152 @@
+package templatesimulators
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"go.uber.org/zap"
+)
+
+type stationState struct {
+	station    template.Station
+	hasPart    bool
+	processing bool
+	remaining  int64
+	repair     int64
+	blocked    bool
+	starved    bool
+	buffer     int32
+	good       int32
+	scrap      int32
+	planned    int64
+	downtime   int64
+}
+
+type productionLineSimulator struct {
+	logger   *zap.Logger
+	node     opcnode.OpcTemplateNode
+	template template.ProductionLineTemplate
+	reader   valuecomputers.NodeValueReader
+	random   *rand.Rand
+	stations []*stationState
+}
+
+func (s *productionLineSimulator) Init() {
+	s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	s.stations = make([]*stationState, len(s.template.Stations))
+	for i, st := range s.template.Stations {
+		s.stations[i] = &stationState{station: st}
+	}
+}
+
+func (s *productionLineSimulator) Step() map[string]waveformvalue.WaveformPointValue {
+	dt := int64(s.node.TickFrequency)
+
+	// stations are advanced from the end of the line, this
+	// way parts move up when room is made downstream
+	for i := len(s.stations) - 1; i >= 0; i-- {
+		s.advance(i, dt)
+	}
+
+	res := make(map[string]waveformvalue.WaveformPointValue)
+	for i, st := range s.stations {
+		availability, performance, quality := st.getOee()
+		res[template.GetStationSignal(st.station, template.GoodCountSignal)] = &waveformvalue.IntegerValue{Value: st.good}
+		res[template.GetStationSignal(st.station, template.ScrapCountSignal)] = &waveformvalue.IntegerValue{Value: st.scrap}
+		res[template.GetStationSignal(st.station, template.BlockedSignal)] = &waveformvalue.Transition{Value: st.blocked}
+		res[template.GetStationSignal(st.station, template.StarvedSignal)] = &waveformvalue.Transition{Value: st.starved}
+		res[template.GetStationSignal(st.station, template.DownSignal)] = &waveformvalue.Transition{Value: st.repair > 0}
+		if i < len(s.stations)-1 {
+			res[template.GetStationSignal(st.station, template.BufferSignal)] = &waveformvalue.IntegerValue{Value: st.buffer}
+		}
+		res[template.GetStationSignal(st.station, template.AvailabilitySignal)] = &waveformvalue.DoubleValue{Value: availability}
+		res[template.GetStationSignal(st.station, template.PerformanceSignal)] = &waveformvalue.DoubleValue{Value: performance}
+		res[template.GetStationSignal(st.station, template.QualitySignal)] = &waveformvalue.DoubleValue{Value: quality}
+		res[template.GetStationSignal(st.station, template.OeeSignal)] = &waveformvalue.DoubleValue{Value: availability * performance * quality}
+	}
+	return res
+}
+
+func (s *productionLineSimulator) advance(i int, dt int64) {
+	st := s.stations[i]
+	st.planned += dt
+	st.blocked = false
+	st.starved = false
+
+	// a station that is down is repaired before it continues
+	if st.repair > 0 {
+		st.repair -= dt
+		st.downtime += dt
+		return
+	}
+	if st.station.MTBF > 0 && s.random.Float64() < float64(dt)/float64(st.station.MTBF) {
+		s.logger.Debug(fmt.Sprintf("station %s of %s failed", st.station.Name, s.node.Label))
+		st.repair = int64(s.random.ExpFloat64() * float64(st.station.MTTR))
+		return
+	}
+
+	if st.processing {
+		st.remaining -= dt
+		if st.remaining <= 0 {
+			st.processing = false
+			if s.random.Float64() < st.station.ScrapRate {
+				st.scrap += 1
+			} else {
+				st.good += 1
+				st.hasPart = true
+			}
+		}
+	}
+
+	// finished parts are passed on to the buffer after the station
+	if st.hasPart {
+		if i == len(s.stations)-1 {
+			st.hasPart = false
+		} else if st.buffer < st.station.BufferCapacity {
+			st.buffer += 1
+			st.hasPart = false
+		} else {
+			st.blocked = true
+			return
+		}
+	}
+
+	// a new part is taken from the buffer before the station
+	if !st.processing {
+		if i > 0 {
+			upstream := s.stations[i-1]
+			if upstream.buffer == 0 {
+				st.starved = true
+				return
+			}
+			upstream.buffer -= 1
+		}
+		st.processing = true
+		cycle := float64(st.station.CycleTime) + s.random.NormFloat64()*float64(st.station.Variance)
+		st.remaining = int64(math.Max(cycle, float64(dt)))
+	}
+}
+
+// getOee computes availability, performance & quality of the station,
+// while nothing is produced the figures are considered ideal
+func (st *stationState) getOee() (float64, float64, float64) {
+	availability, performance, quality := 1.0, 1.0, 1.0
+	runtime := st.planned - st.downtime
+	if st.planned > 0 {
+		availability = float64(runtime) / float64(st.planned)
+	}
+	total := st.good + st.scrap
+	if runtime > 0 {
+		performance = math.Min(1, float64(st.station.CycleTime)*float64(total)/float64(runtime))
+	}
+	if total > 0 {
+		quality = float64(st.good) / float64(total)
+	}
+	return availability, performance, quality
+}
@@ -0,0 +1,72 @@
+package templatesimulators
+
+import (
+	"math"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type tankSimulator struct {
+	logger      *zap.Logger
+	node        opcnode.OpcTemplateNode
+	template    template.TankTemplate
+	reader      valuecomputers.NodeValueReader
+	level       float64
+	valveOpen   bool
+	pumpRunning bool
+}
+
+func (s *tankSimulator) Init() {
+	s.level = math.Max(0, math.Min(s.template.Height, s.template.Level))
+	s.valveOpen = s.template.ValveOpen
+	s.pumpRunning = s.template.PumpRunning
+}
+
+func (s *tankSimulator) Step() map[string]waveformvalue.WaveformPointValue {
+	dt := float64(s.node.TickFrequency) / 1000
+	if v, ok := takeWrite(s.reader, s.node, template.InletValveSignal); ok {
+		s.valveOpen = v != 0
+	}
+	if v, ok := takeWrite(s.reader, s.node, template.OutletPumpSignal); ok {
+		s.pumpRunning = v != 0
+	}
+
+	// the valve & the pump gate the flows, an empty tank can not be pumped
+	inflow := 0.0
+	if s.valveOpen {
+		inflow = s.getFlow(s.template.Inflow, s.template.ValveFlow)
+	}
+	outflow := 0.0
+	if s.pumpRunning && s.level > 0 {
+		outflow = s.getFlow(s.template.Outflow, s.template.PumpFlow)
+	}
+
+	// flows are per hour, the tank spills over above its height
+	s.level += (inflow - outflow) / 3600 * dt / s.template.Area
+	s.level = math.Max(0, math.Min(s.template.Height, s.level))
+
+	return map[string]waveformvalue.WaveformPointValue{
+		template.LevelSignal:      &waveformvalue.DoubleValue{Value: s.level},
+		template.InflowSignal:     &waveformvalue.DoubleValue{Value: inflow},
+		template.OutflowSignal:    &waveformvalue.DoubleValue{Value: outflow},
+		template.InletValveSignal: &waveformvalue.Transition{Value: s.valveOpen},
+		template.OutletPumpSignal: &waveformvalue.Transition{Value: s.pumpRunning},
+		template.HighSwitchSignal: &waveformvalue.Transition{Value: s.level >= s.template.HighLevel},
+		template.LowSwitchSignal:  &waveformvalue.Transition{Value: s.level <= s.template.LowLevel},
+	}
+}
+
+func (s *tankSimulator) getFlow(source *uuid.UUID, fallback float64) float64 {
+	if source == nil {
+		return fallback
+	}
+	if v, ok := readNumeric(s.reader, *source); ok {
+		return math.Max(0, v)
+	}
+	return 0
+}
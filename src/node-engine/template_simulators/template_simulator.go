@@ -0,0 +1,86 @@
+package templatesimulators
+
+import (
+	"fmt"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TemplateSimulator advances the simulation of a template by one
+// tick & returns the value of its signals, keyed by signal
+type TemplateSimulator interface {
+	Init()
+	Step() map[string]waveformvalue.WaveformPointValue
+}
+
+func MakeTemplateSimulator(n opcnode.OpcTemplateNode, r valuecomputers.NodeValueReader, l *zap.Logger) *TemplateSimulator {
+	log := l.Named("TEMPLATE")
+	switch t := n.Template.(type) {
+	case template.ControlLoopTemplate:
+		var s TemplateSimulator = &controlLoopSimulator{
+			logger:   log,
+			node:     n,
+			template: t,
+			reader:   r,
+		}
+		return &s
+	case template.TankTemplate:
+		var s TemplateSimulator = &tankSimulator{
+			logger:   log,
+			node:     n,
+			template: t,
+			reader:   r,
+		}
+		return &s
+	case template.MotorTemplate:
+		var s TemplateSimulator = &motorSimulator{
+			logger:   log,
+			node:     n,
+			template: t,
+			reader:   r,
+		}
+		return &s
+	case template.PackMLTemplate:
+		var s TemplateSimulator = &packMLSimulator{
+			logger:   log,
+			node:     n,
+			template: t,
+			reader:   r,
+		}
+		return &s
+	case template.ProductionLineTemplate:
+		var s TemplateSimulator = &productionLineSimulator{
+			logger:   log,
+			node:     n,
+			template: t,
+			reader:   r,
+		}
+		return &s
+	}
+
+	log.Warn(fmt.Sprintf("unrecognized template %T for %s", n.Template, opcnode.ToDebugString(&n)))
+	return nil
+}
+
+// readNumeric reads the value of a node as a number, booleans are mapped to 0 & 1
+func readNumeric(r valuecomputers.NodeValueReader, id uuid.UUID) (float64, bool) {
+	v, found := r.GetNodeValue(id)
+	if !found {
+		return 0, false
+	}
+	return waveformvalue.ToNumeric(v)
+}
+
+// takeWrite hands out the pending client write of a signal as a number
+func takeWrite(r valuecomputers.NodeValueReader, n opcnode.OpcTemplateNode, key string) (float64, bool) {
+	v, found := r.TakeNodeWrite(n.GetSignalId(key))
+	if !found {
+		return 0, false
+	}
+	return waveformvalue.ToNumeric(v)
+}
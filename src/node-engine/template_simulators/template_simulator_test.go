@@ -0,0 +1,221 @@
+package templatesimulators_test
+
+import (
+	"math"
+	"testing"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/template"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	templatesimulators "github.com/AndreiLacatos/opc-engine/node-engine/template_simulators"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// nodeValues is a NodeValueReader holding the pending client writes of the signals
+type nodeValues struct {
+	writes map[uuid.UUID]waveformvalue.WaveformPointValue
+}
+
+func (r *nodeValues) GetNodeValue(uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	return nil, false
+}
+
+func (r *nodeValues) GetNodeEdges(uuid.UUID) (valuecomputers.EdgeCount, bool) {
+	return valuecomputers.EdgeCount{}, false
+}
+
+func (r *nodeValues) TakeNodeWrite(id uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	v, found := r.writes[id]
+	delete(r.writes, id)
+	return v, found
+}
+
+// simulate writes the given signals & steps the simulation, returning the signal values of every step
+func simulate(t *testing.T, tickFrequency int32, tpl template.Template, writes map[string]waveformvalue.WaveformPointValue, steps int) []map[string]any {
+	n := opcnode.CreateTemplateNode(uuid.MustParse("6b8d0f2a-4c6e-4a8b-9d1f-3a5c7e9b1d3f"), "Template", tickFrequency, tpl)
+	r := &nodeValues{writes: make(map[uuid.UUID]waveformvalue.WaveformPointValue)}
+	for key, v := range writes {
+		r.writes[n.GetSignalId(key)] = v
+	}
+	s := templatesimulators.MakeTemplateSimulator(*n, r, zap.NewNop())
+	if s == nil {
+		t.Fatal("expected a template simulator, got none")
+	}
+
+	(*s).Init()
+	res := make([]map[string]any, steps)
+	for i := range res {
+		res[i] = make(map[string]any)
+		for key, v := range (*s).Step() {
+			res[i][key] = v.GetValue()
+		}
+	}
+	return res
+}
+
+func TestControlLoop_SetpointWrite_ProcessValueSettlesOnSetpoint(t *testing.T) {
+	steps := simulate(t, 50, template.ControlLoopTemplate{
+		Mode:         template.Auto,
+		Kp:           1.0,
+		Ki:           5.0,
+		OutputMax:    100.0,
+		ProcessGain:  1.0,
+		TimeConstant: 200,
+	}, map[string]waveformvalue.WaveformPointValue{
+		template.SetpointSignal: &waveformvalue.DoubleValue{Value: 10.0},
+	}, 40)
+
+	first, last := steps[0], steps[len(steps)-1]
+	if v := first[template.SetpointSignal]; v != 10.0 {
+		t.Errorf("expected written setpoint 10, got %v", v)
+	}
+	if v := first[template.ModeSignal]; v != int32(template.Auto) {
+		t.Errorf("expected mode %d, got %v", template.Auto, v)
+	}
+	if v := first[template.ProcessValueSignal]; v != 0.0 {
+		t.Errorf("expected process value to start at 0, got %v", v)
+	}
+	if v := last[template.ProcessValueSignal].(float64); math.Abs(v-10.0) > 0.5 {
+		t.Errorf("expected process value to settle on 10, got %f", v)
+	}
+}
+
+func TestTank_ValveOpen_FillsUntilHighSwitch(t *testing.T) {
+	steps := simulate(t, 100, template.TankTemplate{
+		Area:      1.0,
+		Height:    2.0,
+		Level:     1.0,
+		ValveFlow: 3600.0,
+		PumpFlow:  3600.0,
+		ValveOpen: true,
+		HighLevel: 1.8,
+		LowLevel:  0.2,
+	}, nil, 12)
+
+	for i, s := range steps {
+		level := math.Min(2.0, 1.0+0.1*float64(i+1))
+		if v := s[template.LevelSignal].(float64); math.Abs(v-level) > 0.001 {
+			t.Errorf("expected level %f at step %d, got %f", level, i+1, v)
+		}
+		high := level > 1.799
+		if v := s[template.HighSwitchSignal]; v != high {
+			t.Errorf("expected high switch at step %d to be %v, got %v", i+1, high, v)
+		}
+		if v := s[template.LowSwitchSignal]; v != false {
+			t.Errorf("expected low switch at step %d to be off, got %v", i+1, v)
+		}
+	}
+}
+
+func TestTank_PumpRunning_EmptiesUntilLowSwitch(t *testing.T) {
+	steps := simulate(t, 100, template.TankTemplate{
+		Area:      1.0,
+		Height:    2.0,
+		Level:     0.5,
+		ValveFlow: 3600.0,
+		PumpFlow:  3600.0,
+		HighLevel: 1.8,
+		LowLevel:  0.2,
+	}, map[string]waveformvalue.WaveformPointValue{
+		template.OutletPumpSignal: &waveformvalue.Transition{Value: true},
+	}, 7)
+
+	last := steps[len(steps)-1]
+	if v := last[template.LevelSignal]; v != 0.0 {
+		t.Errorf("expected the tank to be empty, got %v", v)
+	}
+	if v := last[template.OutflowSignal]; v != 0.0 {
+		t.Errorf("expected no outflow from an empty tank, got %v", v)
+	}
+	if v := last[template.LowSwitchSignal]; v != true {
+		t.Errorf("expected low switch to be on, got %v", v)
+	}
+}
+
+func TestMotor_StartCommand_FeedbackDelayedAndSpeedRamps(t *testing.T) {
+	steps := simulate(t, 100, template.MotorTemplate{
+		RatedSpeed:          1450.0,
+		AccelerationTime:    1000,
+		DecelerationTime:    2000,
+		FeedbackDelay:       300,
+		RatedCurrent:        10.0,
+		StartingCurrent:     6.0,
+		NoLoadCurrent:       0.3,
+		Load:                0.8,
+		AmbientTemperature:  25.0,
+		TemperatureRise:     60.0,
+		ThermalTimeConstant: 60000,
+	}, map[string]waveformvalue.WaveformPointValue{
+		template.CommandSignal: &waveformvalue.Transition{Value: true},
+	}, 13)
+
+	for i, s := range steps {
+		if v := s[template.RunningSignal]; v != (i >= 3) {
+			t.Errorf("expected running feedback at step %d to be %v, got %v", i+1, i >= 3, v)
+		}
+		speed := math.Min(1450.0, 145.0*float64(i+1))
+		if v := s[template.SpeedSignal].(float64); math.Abs(v-speed) > 0.001 {
+			t.Errorf("expected speed %f at step %d, got %f", speed, i+1, v)
+		}
+	}
+	if v := steps[0][template.CurrentSignal].(float64); v < 50.0 {
+		t.Errorf("expected inrush current above 50A, got %f", v)
+	}
+	if v := steps[len(steps)-1][template.CurrentSignal].(float64); math.Abs(v-8.6) > 0.001 {
+		t.Errorf("expected load current of 8.6A at rated speed, got %f", v)
+	}
+}
+
+func TestPackML_StartCommand_RunsThroughStartingToExecute(t *testing.T) {
+	steps := simulate(t, 100, template.PackMLTemplate{
+		InitialState: template.Idle,
+		Durations: map[template.PackMLState]int64{
+			template.Starting:   300,
+			template.Completing: 200,
+		},
+		ExecuteDuration: 400,
+	}, map[string]waveformvalue.WaveformPointValue{
+		template.CommandSignal: &waveformvalue.IntegerValue{Value: int32(template.StartCommand)},
+	}, 12)
+
+	expected := []template.PackMLState{
+		template.Starting, template.Starting, template.Starting, template.Execute,
+		template.Execute, template.Execute, template.Execute, template.Completing,
+		template.Completing, template.Complete, template.Complete, template.Complete,
+	}
+	for i, state := range expected {
+		if v := steps[i][template.StateSignal]; v != int32(state) {
+			t.Errorf("expected state %d at step %d, got %v", state, i+1, v)
+		}
+	}
+}
+
+func TestProductionLine_SlowSecondStation_FirstStationBlocked(t *testing.T) {
+	first := template.Station{Name: "Press", CycleTime: 100, BufferCapacity: 2}
+	second := template.Station{Name: "Packer", CycleTime: 300}
+	steps := simulate(t, 100, template.ProductionLineTemplate{
+		Stations: []template.Station{first, second},
+	}, nil, 13)
+
+	last := steps[len(steps)-1]
+	signal := func(s template.Station, key string) any {
+		return last[template.GetStationSignal(s, key)]
+	}
+	if v := signal(first, template.BlockedSignal); v != true {
+		t.Errorf("expected first station to be blocked, got %v", v)
+	}
+	if v := signal(first, template.BufferSignal); v != int32(2) {
+		t.Errorf("expected full buffer after the first station, got %v", v)
+	}
+	if v := signal(second, template.StarvedSignal); v != false {
+		t.Errorf("expected second station not to be starved, got %v", v)
+	}
+	if v := signal(second, template.GoodCountSignal); v != int32(3) {
+		t.Errorf("expected 3 parts produced by the second station, got %v", v)
+	}
+	if v := signal(second, template.OeeSignal).(float64); math.Abs(v-0.75) > 0.1 {
+		t.Errorf("expected OEE of the second station around 0.75, got %f", v)
+	}
+}
@@ -0,0 +1,68 @@
+package nodeengine
+
+import (
+	"sync"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/trigger"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
+)
+
+// triggerEvaluator checks the values emitted for the source nodes
+// of the triggers against the previous value of the same node
+type triggerEvaluator struct {
+	lock     sync.Mutex
+	triggers map[uuid.UUID][]trigger.Trigger
+	previous map[uuid.UUID]float64
+}
+
+func newTriggerEvaluator(t []trigger.Trigger) *triggerEvaluator {
+	return &triggerEvaluator{
+		triggers: groupTriggers(t),
+		previous: make(map[uuid.UUID]float64),
+	}
+}
+
+// SetTriggers replaces the triggers, previous values are kept
+func (t *triggerEvaluator) SetTriggers(triggers []trigger.Trigger) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.triggers = groupTriggers(triggers)
+}
+
+// Evaluate returns the actions of the triggers fired by the new value of
+// the node, the first value of a node never fires as it has no previous
+func (t *triggerEvaluator) Evaluate(id uuid.UUID, v waveformvalue.WaveformPointValue) []trigger.Action {
+	current, ok := waveformvalue.ToNumeric(v)
+	if !ok {
+		return nil
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	triggers, found := t.triggers[id]
+	if !found {
+		return nil
+	}
+	previous, known := t.previous[id]
+	t.previous[id] = current
+	if !known {
+		return nil
+	}
+
+	actions := make([]trigger.Action, 0)
+	for _, tr := range triggers {
+		if tr.IsMetBy(previous, current) {
+			actions = append(actions, tr.Actions...)
+		}
+	}
+	return actions
+}
+
+func groupTriggers(t []trigger.Trigger) map[uuid.UUID][]trigger.Trigger {
+	res := make(map[uuid.UUID][]trigger.Trigger)
+	for _, tr := range t {
+		res[tr.Source] = append(res[tr.Source], tr)
+	}
+	return res
+}
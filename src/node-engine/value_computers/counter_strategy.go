@@ -0,0 +1,61 @@
+package valuecomputers
+
+import (
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type counterStrategyCalculator struct {
+	logger *zap.Logger
+	id     uuid.UUID
+	meta   waveform.CounterWaveformMeta
+	reader NodeValueReader
+	count  int32
+	seen   *EdgeCount
+}
+
+func (c *counterStrategyCalculator) Init() {
+	c.count = 0
+	c.seen = nil
+}
+
+func (c *counterStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// the edges of the source are tallied as its values are set, so
+	// none is missed between ticks; the edges the source had before
+	// the first tick of the counter are not counted
+	edges, _ := c.reader.GetNodeEdges(c.meta.Source)
+	if c.seen != nil {
+		rising := int32(edges.Rising - c.seen.Rising)
+		falling := int32(edges.Falling - c.seen.Falling)
+		switch c.meta.Edge {
+		case waveform.BothEdges:
+			c.count += rising + falling
+		case waveform.RisingEdge:
+			c.count += rising
+		case waveform.FallingEdge:
+			c.count += falling
+		}
+	}
+	c.seen = &edges
+
+	// clients reset the count by writing the value to continue from
+	if w, found := c.reader.TakeNodeWrite(c.id); found {
+		if n, ok := waveformvalue.ToNumeric(w); ok {
+			c.count = int32(n)
+		}
+	}
+	if isSet(c.reader, c.meta.Reset) {
+		c.count = 0
+	}
+
+	if c.meta.ResetAt > 0 && c.count >= c.meta.ResetAt {
+		if c.meta.Rollover {
+			c.count %= c.meta.ResetAt
+		} else {
+			c.count = 0
+		}
+	}
+	return &waveformvalue.IntegerValue{Value: c.count}
+}
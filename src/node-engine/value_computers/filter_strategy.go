@@ -0,0 +1,75 @@
+package valuecomputers
+
+import (
+	"math"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type filterStrategyCalculator struct {
+	logger   *zap.Logger
+	waveform waveform.Waveform
+	meta     waveform.FilterWaveformMeta
+	reader   NodeValueReader
+	value    float64
+	settled  bool
+	samples  []float64
+}
+
+func (c *filterStrategyCalculator) Init() {
+	c.value = 0
+	c.settled = false
+	c.samples = make([]float64, 0)
+}
+
+func (c *filterStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	v, _ := c.reader.GetNodeValue(c.meta.Source)
+	input, ok := waveformvalue.ToNumeric(v)
+	if !ok {
+		// the input has not published anything yet
+		return &waveformvalue.DoubleValue{Value: c.value}
+	}
+	if !c.settled {
+		// filters start settled on the first input value
+		c.value = input
+		c.settled = true
+	}
+
+	// every call advances the simulation by one tick
+	dt := float64(c.waveform.TickFrequency) / 1000
+	switch c.meta.Filter {
+	case waveform.FirstOrderLagFilter:
+		timeConstant := float64(c.meta.TimeConstant) / 1000
+		c.value += (input - c.value) * dt / (timeConstant + dt)
+	case waveform.DeadTimeFilter:
+		// the output lags the input by a fixed number of ticks,
+		// until enough samples are buffered the oldest one is held
+		c.push(input, c.getTickCount(c.meta.Delay)+1)
+		c.value = c.samples[0]
+	case waveform.MovingAverageFilter:
+		c.push(input, max(c.getTickCount(c.meta.Window), 1))
+		sum := 0.0
+		for _, s := range c.samples {
+			sum += s
+		}
+		c.value = sum / float64(len(c.samples))
+	case waveform.ExponentialSmoothingFilter:
+		alpha := math.Max(0, math.Min(1, c.meta.Alpha))
+		c.value = alpha*input + (1-alpha)*c.value
+	}
+
+	return &waveformvalue.DoubleValue{Value: c.value}
+}
+
+func (c *filterStrategyCalculator) push(v float64, size int) {
+	c.samples = append(c.samples, v)
+	if len(c.samples) > size {
+		c.samples = c.samples[len(c.samples)-size:]
+	}
+}
+
+func (c *filterStrategyCalculator) getTickCount(d int64) int {
+	return int(d / int64(c.waveform.TickFrequency))
+}
@@ -0,0 +1,84 @@
+package valuecomputers
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type markovStrategyCalculator struct {
+	logger   *zap.Logger
+	waveform waveform.Waveform
+	meta     waveform.MarkovWaveformMeta
+	random   *rand.Rand
+	state    int
+	elapsed  int64
+	dwell    int64
+}
+
+func (c *markovStrategyCalculator) Init() {
+	seed := time.Now().UnixNano()
+	if c.meta.Seed != nil {
+		seed = *c.meta.Seed
+	}
+	c.random = rand.New(rand.NewSource(seed))
+	c.enter(c.meta.Initial)
+}
+
+func (c *markovStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// the chain is not bound to the waveform cycle, it
+	// moves on whenever the dwell time of a state elapsed,
+	// a state is emitted at least once even without dwell time
+	value := c.meta.States[c.state].Value
+	c.elapsed += int64(c.waveform.TickFrequency)
+	if c.elapsed >= c.dwell {
+		c.enter(c.getNextState())
+	}
+	return value
+}
+
+func (c *markovStrategyCalculator) enter(s int) {
+	c.state = s
+	c.elapsed = 0
+	c.dwell = c.sampleDwell(c.meta.States[s].Dwell)
+}
+
+func (c *markovStrategyCalculator) getNextState() int {
+	weights := c.meta.Transitions[c.state]
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		// absorbing state
+		return c.state
+	}
+
+	r := c.random.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(weights) - 1
+}
+
+func (c *markovStrategyCalculator) sampleDwell(d waveform.DwellTime) int64 {
+	var dwell float64
+	switch d.Distribution {
+	case waveform.FixedDwell:
+		dwell = float64(d.Mean)
+	case waveform.UniformDwell:
+		dwell = float64(d.Min) + c.random.Float64()*float64(d.Max-d.Min)
+	case waveform.ExponentialDwell:
+		dwell = c.random.ExpFloat64() * float64(d.Mean)
+	case waveform.NormalDwell:
+		dwell = float64(d.Mean) + c.random.NormFloat64()*float64(d.StdDev)
+	}
+	return int64(math.Max(0, dwell))
+}
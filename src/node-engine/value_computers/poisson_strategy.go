@@ -0,0 +1,62 @@
+package valuecomputers
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type poissonStrategyCalculator struct {
+	logger    *zap.Logger
+	waveform  waveform.Waveform
+	meta      waveform.TransitionWaveformMeta
+	random    *rand.Rand
+	elapsed   int64
+	nextPulse float64
+	pulseEnd  int64
+}
+
+func (c *poissonStrategyCalculator) Init() {
+	seed := time.Now().UnixNano()
+	if c.meta.Seed != nil {
+		seed = *c.meta.Seed
+	}
+	c.random = rand.New(rand.NewSource(seed))
+	c.elapsed = 0
+	c.pulseEnd = 0
+	c.nextPulse = c.getInterval()
+}
+
+func (c *poissonStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// pulses are not bound to the waveform cycle, they start on the
+	// first tick after their scheduled time & last for the pulse width,
+	// arrivals are scheduled from the previous arrival so the pulse width
+	// does not lower the rate, overlapping pulses merge into one
+	for float64(c.elapsed) >= c.nextPulse {
+		// pulses last at least a tick, otherwise they would not be seen
+		width := max(c.meta.PulseWidth, int64(c.waveform.TickFrequency))
+		c.pulseEnd = c.elapsed + width
+		if c.random.Float64() < c.meta.Burstiness {
+			c.nextPulse += float64(2 * width)
+		} else {
+			c.nextPulse += c.getInterval()
+		}
+	}
+	on := c.elapsed < c.pulseEnd
+	c.elapsed += int64(c.waveform.TickFrequency)
+	return &waveformvalue.Transition{Value: on}
+}
+
+// getInterval draws the time until the next burst, bursts are rarer
+// than pulses so that on average pulses occur at the configured rate
+func (c *poissonStrategyCalculator) getInterval() float64 {
+	rate := c.meta.Rate * (1 - c.meta.Burstiness)
+	if rate <= 0 {
+		return math.Inf(1)
+	}
+	return c.random.ExpFloat64() * 60000 / rate
+}
@@ -0,0 +1,90 @@
+package valuecomputers
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type scheduleStrategyCalculator struct {
+	logger *zap.Logger
+	meta   waveform.ScheduleWaveformMeta
+	clock  func() time.Time
+}
+
+func (c *scheduleStrategyCalculator) Init() {
+	if c.clock == nil {
+		c.clock = time.Now
+	}
+	if c.meta.Location == nil {
+		c.meta.Location = time.Local
+	}
+
+	// entries are evaluated in chronological order, they are sorted
+	// on a copy as the meta is shared with the rest of the structure
+	days := make(map[time.Weekday][]waveform.ScheduleEntry, len(c.meta.Days))
+	for d, e := range c.meta.Days {
+		days[d] = sortEntries(e)
+	}
+	holidays := make([]waveform.ScheduleHoliday, len(c.meta.Holidays))
+	for i, h := range c.meta.Holidays {
+		if len(h.Entries) == 0 {
+			// holidays without explicit entries hold the default value all day
+			h.Entries = []waveform.ScheduleEntry{
+				{
+					TimeOfDay: 0,
+					Value:     c.meta.Default,
+				},
+			}
+		}
+		h.Entries = sortEntries(h.Entries)
+		holidays[i] = h
+	}
+	c.meta.Days = days
+	c.meta.Holidays = holidays
+}
+
+func (c *scheduleStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// schedules are not bound to the waveform cycle, the
+	// value is always derived from the current wall-clock time
+	now := c.clock().In(c.meta.Location)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, c.meta.Location)
+	timeOfDay := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute +
+		time.Duration(now.Second())*time.Second + time.Duration(now.Nanosecond())
+
+	// look for the last switch point before now, when the current day
+	// has none the value carries over from the preceding days
+	day := midnight
+	for i := 0; i <= 7; i++ {
+		entries := c.getEntriesOfDay(day)
+		for j := len(entries) - 1; j >= 0; j-- {
+			if i > 0 || entries[j].TimeOfDay <= timeOfDay {
+				return entries[j].Value
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return c.meta.Default
+}
+
+func (c *scheduleStrategyCalculator) getEntriesOfDay(d time.Time) []waveform.ScheduleEntry {
+	for _, h := range c.meta.Holidays {
+		if h.Year == d.Year() && h.Month == d.Month() && h.Day == d.Day() {
+			return h.Entries
+		}
+	}
+	return c.meta.Days[d.Weekday()]
+}
+
+func sortEntries(e []waveform.ScheduleEntry) []waveform.ScheduleEntry {
+	res := slices.Clone(e)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].TimeOfDay < res[j].TimeOfDay
+	})
+	return res
+}
@@ -0,0 +1,79 @@
+package valuecomputers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+func TestScheduleStrategy(t *testing.T) {
+	value := func(v float64) waveformvalue.WaveformPointValue {
+		return &waveformvalue.DoubleValue{Value: v}
+	}
+	entry := func(h int, v float64) waveform.ScheduleEntry {
+		return waveform.ScheduleEntry{TimeOfDay: time.Duration(h) * time.Hour, Value: value(v)}
+	}
+	bucharest, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := waveform.ScheduleWaveformMeta{
+		Location:  bucharest,
+		ValueType: waveform.DoubleValue,
+		Default:   value(0),
+		Days: map[time.Weekday][]waveform.ScheduleEntry{
+			// entries are sorted on init
+			time.Monday:   {entry(18, 3), entry(6, 7.5)},
+			time.Tuesday:  {entry(6, 8)},
+			time.Saturday: {entry(8, 2)},
+		},
+		Holidays: []waveform.ScheduleHoliday{
+			{Year: 2024, Month: time.March, Day: 5},
+			{Year: 2024, Month: time.March, Day: 6, Entries: []waveform.ScheduleEntry{entry(10, 1)}},
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		now  time.Time
+		want float64
+	}{
+		{"before the first entry of the week", time.Date(2024, time.March, 4, 5, 59, 0, 0, bucharest), 2},
+		{"morning entry", time.Date(2024, time.March, 4, 6, 0, 0, 0, bucharest), 7.5},
+		{"evening entry", time.Date(2024, time.March, 4, 20, 0, 0, 0, bucharest), 3},
+		{"carried over from the previous day", time.Date(2024, time.February, 27, 3, 0, 0, 0, bucharest), 3},
+		{"carried over across days without entries", time.Date(2024, time.March, 2, 7, 0, 0, 0, bucharest), 8},
+		{"holiday without entries holds the default", time.Date(2024, time.March, 5, 12, 0, 0, 0, bucharest), 0},
+		{"holiday before its first entry", time.Date(2024, time.March, 6, 9, 0, 0, 0, bucharest), 0},
+		{"holiday entry", time.Date(2024, time.March, 6, 11, 0, 0, 0, bucharest), 1},
+		{"evaluated in the schedule location", time.Date(2024, time.March, 4, 4, 30, 0, 0, time.UTC), 7.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := scheduleStrategyCalculator{
+				logger: zap.NewNop(),
+				meta:   meta,
+				clock:  func() time.Time { return tc.now },
+			}
+			c.Init()
+
+			if v := c.GetValueAtTick(0).GetValue(); v != tc.want {
+				t.Errorf("expected %f, got %v", tc.want, v)
+			}
+		})
+	}
+
+	t.Run("meta left untouched", func(t *testing.T) {
+		c := scheduleStrategyCalculator{logger: zap.NewNop(), meta: meta}
+		c.Init()
+
+		if meta.Days[time.Monday][0].TimeOfDay != 18*time.Hour {
+			t.Errorf("expected the entries of the meta to keep their order, got %+v", meta.Days[time.Monday])
+		}
+		if meta.Holidays[0].Entries != nil {
+			t.Errorf("expected the holiday of the meta to keep no entries, got %+v", meta.Holidays[0].Entries)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package valuecomputers
+
+import (
+	"math"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type totalizerStrategyCalculator struct {
+	logger   *zap.Logger
+	id       uuid.UUID
+	waveform waveform.Waveform
+	meta     waveform.TotalizerWaveformMeta
+	reader   NodeValueReader
+	total    float64
+	started  bool
+}
+
+func (c *totalizerStrategyCalculator) Init() {
+	c.total = 0
+	c.started = false
+}
+
+func (c *totalizerStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// clients reset the total by writing the value to continue from
+	if w, found := c.reader.TakeNodeWrite(c.id); found {
+		if n, ok := waveformvalue.ToNumeric(w); ok {
+			c.total = n
+			return &waveformvalue.DoubleValue{Value: c.total}
+		}
+	}
+	if isSet(c.reader, c.meta.Reset) {
+		c.total = 0
+		return &waveformvalue.DoubleValue{Value: c.total}
+	}
+
+	// every tick adds the input accumulated since the previous one
+	v, _ := c.reader.GetNodeValue(c.meta.Source)
+	if input, ok := waveformvalue.ToNumeric(v); ok && c.started {
+		dt := float64(c.waveform.TickFrequency) / 1000
+		c.total += input * c.meta.Scale * dt
+	}
+	c.started = true
+
+	if c.meta.ResetAt > 0 && c.total >= c.meta.ResetAt {
+		if c.meta.Rollover {
+			c.total = math.Mod(c.total, c.meta.ResetAt)
+		} else {
+			c.total = 0
+		}
+	}
+	return &waveformvalue.DoubleValue{Value: c.total}
+}
@@ -0,0 +1,51 @@
+package valuecomputers
+
+import (
+	"math"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"go.uber.org/zap"
+)
+
+type trackingStrategyCalculator struct {
+	logger   *zap.Logger
+	waveform waveform.Waveform
+	meta     waveform.TrackingWaveformMeta
+	reader   NodeValueReader
+	value    float64
+	velocity float64
+}
+
+func (c *trackingStrategyCalculator) Init() {
+	c.value = c.meta.Initial
+	c.velocity = 0
+}
+
+func (c *trackingStrategyCalculator) GetValueAtTick(t int64) waveformvalue.WaveformPointValue {
+	// without a target value the node holds its value
+	v, _ := c.reader.GetNodeValue(c.meta.Source)
+	target, ok := waveformvalue.ToNumeric(v)
+	if !ok {
+		return &waveformvalue.DoubleValue{Value: c.value}
+	}
+
+	// every call advances the simulation by one tick
+	dt := float64(c.waveform.TickFrequency) / 1000
+	timeConstant := math.Max(float64(c.meta.TimeConstant)/1000, dt)
+	switch c.meta.Mode {
+	case waveform.Ramp:
+		step := c.meta.Rate * dt
+		c.value += math.Max(-step, math.Min(step, target-c.value))
+	case waveform.FirstOrderLag:
+		c.value += (target - c.value) * dt / (timeConstant + dt)
+	case waveform.Overshoot:
+		// second order system, damping below 1 overshoots the target
+		omega := 1 / timeConstant
+		acceleration := omega*omega*(target-c.value) - 2*c.meta.Damping*omega*c.velocity
+		c.velocity += acceleration * dt
+		c.value += c.velocity * dt
+	}
+
+	return &waveformvalue.DoubleValue{Value: c.value}
+}
@@ -6,6 +6,7 @@ import (
 	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
 	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -14,13 +15,42 @@ type ValueComputer interface {
 	GetValueAtTick(t int64) waveformvalue.WaveformPointValue
 }
 
-func MakeValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
+// NodeValueReader provides the current value of other nodes
+// to value computers that derive their value from them, pending
+// client writes are handed out once, to the node that was written
+type NodeValueReader interface {
+	GetNodeValue(uuid.UUID) (waveformvalue.WaveformPointValue, bool)
+	GetNodeEdges(uuid.UUID) (EdgeCount, bool)
+	TakeNodeWrite(uuid.UUID) (waveformvalue.WaveformPointValue, bool)
+}
+
+// EdgeCount is the number of times the value of a node switched
+// between zero & non-zero since the engine started, every value
+// set for the node is counted, not only the ones seen on a tick
+type EdgeCount struct {
+	Rising  int64
+	Falling int64
+}
+
+func MakeValueComputer(n opcnode.OpcValueNode, r NodeValueReader, l *zap.Logger) *ValueComputer {
 	log := l.Named("VALCOMP")
 	switch n.Waveform.WaveformType {
 	case waveform.Transitions:
 		return makeTransitionValueComputer(n, log)
 	case waveform.NumericValues:
 		return makeNumericValueComputer(n, log)
+	case waveform.Schedule:
+		return makeScheduleValueComputer(n, log)
+	case waveform.Tracking:
+		return makeTrackingValueComputer(n, r, log)
+	case waveform.Filter:
+		return makeFilterValueComputer(n, r, log)
+	case waveform.Totalizer:
+		return makeTotalizerValueComputer(n, r, log)
+	case waveform.Counter:
+		return makeCounterValueComputer(n, r, log)
+	case waveform.Markov:
+		return makeMarkovValueComputer(n, log)
 	}
 
 	log.Warn(fmt.Sprintf("unrecognized waveform type %v", n.Waveform.WaveformType))
@@ -28,6 +58,16 @@ func MakeValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
 }
 
 func makeTransitionValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta != nil {
+		if meta, ok := (*n.Waveform.Meta).(waveform.TransitionWaveformMeta); ok && meta.Mode == waveform.PoissonPulses {
+			var c ValueComputer = &poissonStrategyCalculator{
+				logger:   l,
+				waveform: n.Waveform,
+				meta:     meta,
+			}
+			return &c
+		}
+	}
 	var c ValueComputer = &transitionStrategyCalculator{
 		logger:   l,
 		waveform: n.Waveform,
@@ -36,6 +76,10 @@ func makeTransitionValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueCo
 }
 
 func makeNumericValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing smoothing for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
 	if meta, ok := (*n.Waveform.Meta).(waveform.NumericWaveformMeta); !ok {
 		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
 		return nil
@@ -65,3 +109,115 @@ func makeNumericValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueCompu
 		}
 	}
 }
+
+func makeScheduleValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing schedule for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.ScheduleWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &scheduleStrategyCalculator{
+			logger: l,
+			meta:   meta,
+		}
+		return &c
+	}
+}
+
+func makeTrackingValueComputer(n opcnode.OpcValueNode, r NodeValueReader, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing tracking definition for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.TrackingWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &trackingStrategyCalculator{
+			logger:   l,
+			waveform: n.Waveform,
+			meta:     meta,
+			reader:   r,
+		}
+		return &c
+	}
+}
+
+func makeFilterValueComputer(n opcnode.OpcValueNode, r NodeValueReader, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing filter definition for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.FilterWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &filterStrategyCalculator{
+			logger:   l,
+			waveform: n.Waveform,
+			meta:     meta,
+			reader:   r,
+		}
+		return &c
+	}
+}
+
+func makeTotalizerValueComputer(n opcnode.OpcValueNode, r NodeValueReader, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing totalizer definition for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.TotalizerWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &totalizerStrategyCalculator{
+			logger:   l,
+			id:       n.Id,
+			waveform: n.Waveform,
+			meta:     meta,
+			reader:   r,
+		}
+		return &c
+	}
+}
+
+func makeCounterValueComputer(n opcnode.OpcValueNode, r NodeValueReader, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing counter definition for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.CounterWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &counterStrategyCalculator{
+			logger: l,
+			id:     n.Id,
+			meta:   meta,
+			reader: r,
+		}
+		return &c
+	}
+}
+
+func makeMarkovValueComputer(n opcnode.OpcValueNode, l *zap.Logger) *ValueComputer {
+	if n.Waveform.Meta == nil {
+		l.Warn(fmt.Sprintf("missing markov chain for %s", opcnode.ToDebugString(&n)))
+		return nil
+	}
+	if meta, ok := (*n.Waveform.Meta).(waveform.MarkovWaveformMeta); !ok {
+		l.Warn(fmt.Sprintf("invalid waveform meta for %s", opcnode.ToDebugString(&n)))
+		return nil
+	} else {
+		var c ValueComputer = &markovStrategyCalculator{
+			logger:   l,
+			waveform: n.Waveform,
+			meta:     meta,
+		}
+		return &c
+	}
+}
@@ -0,0 +1,276 @@
+package valuecomputers_test
+
+import (
+	"math"
+	"testing"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	nodeId   = uuid.MustParse("9d3c5a7e-1f2b-4c6d-8e0f-a1b2c3d4e5f6")
+	sourceId = uuid.MustParse("4b7e2f0a-8c1d-4e3f-a5b6-7c8d9e0f1a2b")
+)
+
+// nodeValues is a NodeValueReader holding the published value of the
+// source node & the pending client writes of the computed node
+type nodeValues struct {
+	values map[uuid.UUID]waveformvalue.WaveformPointValue
+	writes map[uuid.UUID]waveformvalue.WaveformPointValue
+}
+
+func (r *nodeValues) GetNodeValue(id uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	v, found := r.values[id]
+	return v, found
+}
+
+func (r *nodeValues) GetNodeEdges(uuid.UUID) (valuecomputers.EdgeCount, bool) {
+	return valuecomputers.EdgeCount{}, false
+}
+
+func (r *nodeValues) TakeNodeWrite(id uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	v, found := r.writes[id]
+	delete(r.writes, id)
+	return v, found
+}
+
+func makeValueComputer(t *testing.T, w waveform.WaveformType, m waveform.WaveformMeta, r valuecomputers.NodeValueReader) valuecomputers.ValueComputer {
+	c := valuecomputers.MakeValueComputer(opcnode.OpcValueNode{
+		Id:    nodeId,
+		Label: "Computed",
+		Waveform: waveform.Waveform{
+			Duration:      100,
+			TickFrequency: 100,
+			WaveformType:  w,
+			Meta:          &m,
+		},
+	}, r, zap.NewNop())
+	if c == nil {
+		t.Fatal("expected a value computer, got none")
+	}
+	(*c).Init()
+	return *c
+}
+
+func TestDerivedValueComputers(t *testing.T) {
+	none := math.NaN()
+	reset := uuid.MustParse("2f4e6d8c-0b1a-4c3e-9d5f-7a9b1c3d5e7f")
+	for _, tc := range []struct {
+		name         string
+		waveformType waveform.WaveformType
+		meta         waveform.WaveformMeta
+		inputs       []float64
+		writes       map[int]float64
+		want         []float64
+	}{
+		{
+			name:         "tracking ramp follows setpoint",
+			waveformType: waveform.Tracking,
+			meta:         waveform.TrackingWaveformMeta{Source: sourceId, Mode: waveform.Ramp, Rate: 10},
+			inputs:       []float64{0, 5, 5, 5, 5, 5, 5},
+			want:         []float64{0, 1, 2, 3, 4, 5, 5},
+		},
+		{
+			name:         "tracking ramp down",
+			waveformType: waveform.Tracking,
+			meta:         waveform.TrackingWaveformMeta{Source: sourceId, Mode: waveform.Ramp, Rate: 20, Initial: 5},
+			inputs:       []float64{0, 0, 0, 0},
+			want:         []float64{3, 1, 0, 0},
+		},
+		{
+			name:         "tracking first order lag",
+			waveformType: waveform.Tracking,
+			meta:         waveform.TrackingWaveformMeta{Source: sourceId, Mode: waveform.FirstOrderLag, TimeConstant: 100},
+			inputs:       []float64{8, 8, 8},
+			want:         []float64{4, 6, 7},
+		},
+		{
+			name:         "tracking without setpoint holds initial value",
+			waveformType: waveform.Tracking,
+			meta:         waveform.TrackingWaveformMeta{Source: sourceId, Mode: waveform.Ramp, Rate: 10, Initial: 3},
+			inputs:       []float64{none, none},
+			want:         []float64{3, 3},
+		},
+		{
+			name:         "dead time delays input",
+			waveformType: waveform.Filter,
+			meta:         waveform.FilterWaveformMeta{Source: sourceId, Filter: waveform.DeadTimeFilter, Delay: 200},
+			inputs:       []float64{2, 2, 2, 6, 6, 6, 6},
+			want:         []float64{2, 2, 2, 2, 2, 6, 6},
+		},
+		{
+			name:         "moving average",
+			waveformType: waveform.Filter,
+			meta:         waveform.FilterWaveformMeta{Source: sourceId, Filter: waveform.MovingAverageFilter, Window: 200},
+			inputs:       []float64{2, 4, 6},
+			want:         []float64{2, 3, 5},
+		},
+		{
+			name:         "exponential smoothing settles on first input",
+			waveformType: waveform.Filter,
+			meta:         waveform.FilterWaveformMeta{Source: sourceId, Filter: waveform.ExponentialSmoothingFilter, Alpha: 0.5},
+			inputs:       []float64{2, 4, 4},
+			want:         []float64{2, 3, 3.5},
+		},
+		{
+			name:         "filter without input",
+			waveformType: waveform.Filter,
+			meta:         waveform.FilterWaveformMeta{Source: sourceId, Filter: waveform.DeadTimeFilter, Delay: 200},
+			inputs:       []float64{none, none},
+			want:         []float64{0, 0},
+		},
+		{
+			name:         "totalizer reset by client write",
+			waveformType: waveform.Totalizer,
+			meta:         waveform.TotalizerWaveformMeta{Source: sourceId, Scale: 1},
+			inputs:       []float64{2, 2, 2, 2, 2},
+			writes:       map[int]float64{3: 0},
+			want:         []float64{0, 0.2, 0.4, 0, 0.2},
+		},
+		{
+			name:         "totalizer rolls over",
+			waveformType: waveform.Totalizer,
+			meta:         waveform.TotalizerWaveformMeta{Source: sourceId, Scale: 1, ResetAt: 0.5, Rollover: true},
+			inputs:       []float64{2, 2, 2, 2},
+			want:         []float64{0, 0.2, 0.4, 0.1},
+		},
+		{
+			name:         "totalizer restarts at reset value",
+			waveformType: waveform.Totalizer,
+			meta:         waveform.TotalizerWaveformMeta{Source: sourceId, Scale: 1, ResetAt: 0.5},
+			inputs:       []float64{2, 2, 2, 2, 2},
+			want:         []float64{0, 0.2, 0.4, 0, 0.2},
+		},
+		{
+			name:         "totalizer reset by node",
+			waveformType: waveform.Totalizer,
+			meta:         waveform.TotalizerWaveformMeta{Source: sourceId, Scale: 10, Reset: &reset},
+			inputs:       []float64{1, 1, 1},
+			want:         []float64{0, 1, 0},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &nodeValues{
+				values: make(map[uuid.UUID]waveformvalue.WaveformPointValue),
+				writes: make(map[uuid.UUID]waveformvalue.WaveformPointValue),
+			}
+			c := makeValueComputer(t, tc.waveformType, tc.meta, r)
+
+			for i, input := range tc.inputs {
+				if !math.IsNaN(input) {
+					r.values[sourceId] = &waveformvalue.DoubleValue{Value: input}
+				}
+				if w, found := tc.writes[i]; found {
+					r.writes[nodeId] = &waveformvalue.DoubleValue{Value: w}
+				}
+				r.values[reset] = &waveformvalue.Transition{Value: i == 2}
+
+				v := c.GetValueAtTick(int64(i * 100)).GetValue().(float64)
+
+				if math.Abs(v-tc.want[i]) > 1e-9 {
+					t.Errorf("expected %f at tick %d, got %f", tc.want[i], i, v)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkovStrategy(t *testing.T) {
+	seed := int64(42)
+	fixed := func(mean int64) waveform.DwellTime {
+		return waveform.DwellTime{Distribution: waveform.FixedDwell, Mean: mean}
+	}
+	states := func(dwell ...int64) []waveform.MarkovState {
+		s := make([]waveform.MarkovState, len(dwell))
+		for i, d := range dwell {
+			s[i] = waveform.MarkovState{Value: &waveformvalue.IntegerValue{Value: int32(i)}, Dwell: fixed(d)}
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		name string
+		meta waveform.MarkovWaveformMeta
+		want []int32
+	}{
+		{
+			name: "fixed dwell times alternate states",
+			meta: waveform.MarkovWaveformMeta{States: states(200, 300), Transitions: [][]float64{{0, 1}, {1, 0}}},
+			want: []int32{0, 0, 1, 1, 1, 0, 0, 1, 1, 1},
+		},
+		{
+			name: "initial state",
+			meta: waveform.MarkovWaveformMeta{States: states(100, 100, 100), Transitions: [][]float64{{0, 1, 0}, {0, 0, 1}, {1, 0, 0}}, Initial: 2},
+			want: []int32{2, 0, 1, 2, 0},
+		},
+		{
+			name: "absorbing state",
+			meta: waveform.MarkovWaveformMeta{States: states(100, 100), Transitions: [][]float64{{0, 1}, {0, 0}}},
+			want: []int32{0, 1, 1, 1},
+		},
+		{
+			name: "initial state without dwell time",
+			meta: waveform.MarkovWaveformMeta{States: states(0, 0), Transitions: [][]float64{{0, 1}, {1, 0}}, Initial: 1},
+			want: []int32{1, 0, 1, 0},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.meta.ValueType = waveform.IntegerValue
+			tc.meta.Seed = &seed
+			c := makeValueComputer(t, waveform.Markov, tc.meta, nil)
+
+			for i, want := range tc.want {
+				if v := c.GetValueAtTick(int64(i * 100)).GetValue(); v != want {
+					t.Errorf("expected state %d at tick %d, got %v", want, i, v)
+				}
+			}
+		})
+	}
+}
+
+func TestPoissonStrategy_SeededPulses_LastForPulseWidth(t *testing.T) {
+	seed := int64(7)
+	meta := waveform.TransitionWaveformMeta{
+		Mode:       waveform.PoissonPulses,
+		Rate:       300,
+		PulseWidth: 200,
+		Seed:       &seed,
+	}
+	sample := func() []bool {
+		c := makeValueComputer(t, waveform.Transitions, meta, nil)
+		res := make([]bool, 30)
+		for i := range res {
+			res[i] = c.GetValueAtTick(int64(i * 100)).GetValue().(bool)
+		}
+		return res
+	}
+
+	samples := sample()
+
+	pulses, width := 0, 0
+	for i, on := range samples {
+		if on {
+			width += 1
+			continue
+		}
+		if width > 0 {
+			if width < 2 {
+				t.Errorf("expected pulse ending at tick %d to last at least 2 ticks, got %d", i, width)
+			}
+			pulses += 1
+			width = 0
+		}
+	}
+	if pulses == 0 {
+		t.Errorf("expected pulses at a rate of 5 per second, got none")
+	}
+	for i, on := range sample() {
+		if on != samples[i] {
+			t.Fatalf("expected the same pulses for the same seed, tick %d differs", i)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package valuecomputers
+
+import (
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/google/uuid"
+)
+
+// isSet tells whether the optional boolean node is currently true
+func isSet(r NodeValueReader, id *uuid.UUID) bool {
+	if id == nil {
+		return false
+	}
+	v, _ := r.GetNodeValue(*id)
+	n, ok := waveformvalue.ToNumeric(v)
+	return ok && n != 0
+}
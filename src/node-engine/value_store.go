@@ -0,0 +1,97 @@
+package nodeengine
+
+import (
+	"sync"
+
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+)
+
+// valueStore holds the current value of every node, as emitted by
+// the engine or written by clients, so that nodes can depend on each other;
+// the values published to the OPC server are kept apart along with their status
+type valueStore struct {
+	lock      sync.RWMutex
+	values    map[uuid.UUID]waveformvalue.WaveformPointValue
+	edges     map[uuid.UUID]valuecomputers.EdgeCount
+	writes    map[uuid.UUID]waveformvalue.WaveformPointValue
+	published map[uuid.UUID]NodeValueChange
+}
+
+func newValueStore() *valueStore {
+	return &valueStore{
+		values:    make(map[uuid.UUID]waveformvalue.WaveformPointValue),
+		edges:     make(map[uuid.UUID]valuecomputers.EdgeCount),
+		writes:    make(map[uuid.UUID]waveformvalue.WaveformPointValue),
+		published: make(map[uuid.UUID]NodeValueChange),
+	}
+}
+
+func (s *valueStore) GetNodeValue(id uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	v, found := s.values[id]
+	return v, found
+}
+
+func (s *valueStore) SetNodeValue(id uuid.UUID, v waveformvalue.WaveformPointValue) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.countEdge(id, v)
+	s.values[id] = v
+}
+
+func (s *valueStore) GetNodeEdges(id uuid.UUID) (valuecomputers.EdgeCount, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	c, found := s.edges[id]
+	return c, found
+}
+
+// countEdge tallies the switch between the current & the new
+// value of a node, if there is one; the caller holds the lock
+func (s *valueStore) countEdge(id uuid.UUID, v waveformvalue.WaveformPointValue) {
+	c := s.edges[id]
+	previous, known := waveformvalue.ToNumeric(s.values[id])
+	current, ok := waveformvalue.ToNumeric(v)
+	if known && ok && (previous != 0) != (current != 0) {
+		if current != 0 {
+			c.Rising += 1
+		} else {
+			c.Falling += 1
+		}
+	}
+	s.edges[id] = c
+}
+
+// WriteNodeValue stores a value written by a client, besides becoming the
+// current value it is kept until the written node takes it
+func (s *valueStore) WriteNodeValue(id uuid.UUID, v waveformvalue.WaveformPointValue) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.countEdge(id, v)
+	s.values[id] = v
+	s.writes[id] = v
+}
+
+func (s *valueStore) TakeNodeWrite(id uuid.UUID) (waveformvalue.WaveformPointValue, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, found := s.writes[id]
+	delete(s.writes, id)
+	return v, found
+}
+
+func (s *valueStore) SetPublishedValue(c NodeValueChange) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.published[c.Node.Id] = c
+}
+
+func (s *valueStore) GetPublishedValue(id uuid.UUID) (NodeValueChange, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	c, found := s.published[id]
+	return c, found
+}
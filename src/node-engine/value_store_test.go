@@ -0,0 +1,62 @@
+package nodeengine
+
+import (
+	"testing"
+
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	valuecomputers "github.com/AndreiLacatos/opc-engine/node-engine/value_computers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCounter_CountsEdgesOfSourceTogglingFasterThanItTicks(t *testing.T) {
+	sourceId := uuid.MustParse("4b7e2f0a-8c1d-4e3f-a5b6-7c8d9e0f1a2b")
+	// values set for the source before each tick of the counter,
+	// the ones before the first tick are not counted
+	toggles := [][]bool{
+		{false, true, false},
+		{true, false},
+		{true, false, true},
+		{},
+	}
+	for _, tc := range []struct {
+		name string
+		edge waveform.EdgeType
+		want []int32
+	}{
+		{"rising edges", waveform.RisingEdge, []int32{0, 1, 3, 3}},
+		{"falling edges", waveform.FallingEdge, []int32{0, 1, 2, 2}},
+		{"both edges", waveform.BothEdges, []int32{0, 2, 5, 5}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newValueStore()
+			var meta waveform.WaveformMeta = waveform.CounterWaveformMeta{Source: sourceId, Edge: tc.edge}
+			c := valuecomputers.MakeValueComputer(opcnode.OpcValueNode{
+				Id:    uuid.MustParse("9d3c5a7e-1f2b-4c6d-8e0f-a1b2c3d4e5f6"),
+				Label: "Count",
+				Waveform: waveform.Waveform{
+					Duration:      100,
+					TickFrequency: 100,
+					WaveformType:  waveform.Counter,
+					Meta:          &meta,
+				},
+			}, s, zap.NewNop())
+			if c == nil {
+				t.Fatal("expected a value computer, got none")
+			}
+			(*c).Init()
+
+			for i, values := range toggles {
+				for _, v := range values {
+					s.SetNodeValue(sourceId, &waveformvalue.Transition{Value: v})
+				}
+
+				if v := (*c).GetValueAtTick(int64(i * 100)).GetValue(); v != tc.want[i] {
+					t.Errorf("expected count %d at tick %d, got %v", tc.want[i], i, v)
+				}
+			}
+		})
+	}
+}
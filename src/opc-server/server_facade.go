@@ -11,8 +11,10 @@ import (
 type OpcServer interface {
 	Setup() error
 	SetNodeStructure(opc.OpcStructure) error
+	UpdateNodeStructure(opc.StructureDiff) error
 	Start() error
 	Subscribe(chan nodeengine.NodeValueChange)
+	WriteChannel() chan nodeengine.NodeValueWrite
 	Stop() error
 }
 
@@ -21,6 +21,7 @@ import (
 	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
 	"github.com/awcullen/opcua/server"
 	"github.com/awcullen/opcua/ua"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -30,6 +31,7 @@ type opcServerImpl struct {
 	OpcServer   *server.Server
 	Logger      *zap.Logger
 	Unsubscribe chan interface{}
+	Writes      chan nodeengine.NodeValueWrite
 }
 
 func (s *opcServerImpl) Setup() error {
@@ -39,6 +41,7 @@ func (s *opcServerImpl) Setup() error {
 
 	s.Logger.Info("setting up OPC server")
 	s.Unsubscribe = make(chan interface{}, 1)
+	s.Writes = make(chan nodeengine.NodeValueWrite, 64)
 	configJson, err := json.MarshalIndent(s.Config, "", "  ")
 	if err != nil {
 		s.Logger.Error(fmt.Sprintf("failed to convert config to JSON: %v", err))
@@ -120,6 +123,10 @@ func (s *opcServerImpl) Subscribe(c chan nodeengine.NodeValueChange) {
 	}
 }
 
+func (s *opcServerImpl) WriteChannel() chan nodeengine.NodeValueWrite {
+	return s.Writes
+}
+
 func (s *opcServerImpl) Stop() error {
 	if s.OpcServer == nil {
 		return fmt.Errorf("server never set up")
@@ -133,36 +140,129 @@ func (s *opcServerImpl) SetNodeStructure(o opc.OpcStructure) error {
 	if s.OpcServer == nil {
 		return fmt.Errorf("server never set up")
 	}
-	applicationObjects := ua.NewNodeIDNumeric(0, 85)
 	r := opcnode.OpcContainerNode(o.Root)
-	return addNodesRecursively(&r, applicationObjects, s.OpcServer.NamespaceManager(), s.OpcServer)
+	return s.addNodesRecursively(&r, getParentNodeId(uuid.Nil))
 }
 
-func addNodesRecursively(r opcnode.OpcStructureNode, p ua.NodeID, m *server.NamespaceManager, s *server.Server) error {
-	n, err := makeNode(r, p, s)
-	if err != nil {
-		return err
+// UpdateNodeStructure applies the changes of the structure to the address
+// space in place, nodes not affected by the changes are left untouched
+func (s *opcServerImpl) UpdateNodeStructure(d opc.StructureDiff) error {
+	if s.OpcServer == nil {
+		return fmt.Errorf("server never set up")
+	}
+	m := s.OpcServer.NamespaceManager()
+
+	// the added nodes are built before anything is changed, the
+	// address space is left untouched if any of them is invalid
+	added := make([]server.Node, 0)
+	for _, c := range d.Added {
+		nodes, err := s.makeNodesRecursively(c.Node, getParentNodeId(c.Parent))
+		if err != nil {
+			return err
+		}
+		added = append(added, nodes...)
 	}
-	m.AddNode(n)
-	if t, ok := r.(*opcnode.OpcContainerNode); ok {
-		for _, c := range t.Children {
-			if err := addNodesRecursively(c, ua.NewNodeIDGUID(2, t.GetId()), m, s); err != nil {
-				return err
+
+	for _, c := range d.Removed {
+		// children are organized by their folder, the namespace
+		// manager only removes components along with their parent
+		nodes := make([]server.Node, 0)
+		for _, id := range getSubtreeIds(c.Node) {
+			if n, found := m.FindNode(ua.NewNodeIDGUID(2, id)); found {
+				nodes = append(nodes, n)
 			}
 		}
+		if err := m.DeleteNodes(nodes, false); err != nil {
+			return err
+		}
+	}
+	if err := m.AddNodes(added...); err != nil {
+		return err
+	}
+	for _, c := range d.Updated {
+		v, ok := c.Node.(*opcnode.OpcValueNode)
+		if !ok {
+			continue
+		}
+		// the write handler depends on the override of the node
+		if n, found := m.FindVariable(ua.NewNodeIDGUID(2, v.Id)); found {
+			n.SetWriteValueHandler(s.makeWriteHandler(*v))
+		} else {
+			s.Logger.Warn(fmt.Sprintf("updated node %s not found", opcnode.ToDebugString(v)))
+		}
 	}
 	return nil
 }
 
+func (s *opcServerImpl) addNodesRecursively(r opcnode.OpcStructureNode, p ua.NodeID) error {
+	nodes, err := s.makeNodesRecursively(r, p)
+	if err != nil {
+		return err
+	}
+	return s.OpcServer.NamespaceManager().AddNodes(nodes...)
+}
+
+// makeNodesRecursively builds the OPC nodes of a subtree without adding
+// them, so that nothing is added if any of them can not be built
+func (s *opcServerImpl) makeNodesRecursively(r opcnode.OpcStructureNode, p ua.NodeID) ([]server.Node, error) {
+	n, err := makeNode(r, p, s.OpcServer)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := r.(*opcnode.OpcValueNode); ok {
+		n.(*server.VariableNode).SetWriteValueHandler(s.makeWriteHandler(*v))
+	}
+	nodes := []server.Node{n}
+	var children []opcnode.OpcStructureNode
+	switch t := r.(type) {
+	case *opcnode.OpcContainerNode:
+		children = t.Children
+	case *opcnode.OpcTemplateNode:
+		children = t.Children
+	}
+	for _, c := range children {
+		childNodes, err := s.makeNodesRecursively(c, ua.NewNodeIDGUID(2, r.GetId()))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, childNodes...)
+	}
+	return nodes, nil
+}
+
+// getParentNodeId returns the id of the OPC node of a parent, the
+// root of the structure is placed in the objects folder
+func getParentNodeId(id uuid.UUID) ua.NodeID {
+	if id == uuid.Nil {
+		return ua.NewNodeIDNumeric(0, 85)
+	}
+	return ua.NewNodeIDGUID(2, id)
+}
+
+func getSubtreeIds(r opcnode.OpcStructureNode) []uuid.UUID {
+	res := []uuid.UUID{r.GetId()}
+	var children []opcnode.OpcStructureNode
+	switch t := r.(type) {
+	case *opcnode.OpcContainerNode:
+		children = t.Children
+	case *opcnode.OpcTemplateNode:
+		children = t.Children
+	}
+	for _, c := range children {
+		res = append(res, getSubtreeIds(c)...)
+	}
+	return res
+}
+
 func (s *opcServerImpl) updateNodeValue(c nodeengine.NodeValueChange) {
-	s.Logger.Debug(fmt.Sprintf("received change: %f on %s",
+	s.Logger.Debug(fmt.Sprintf("received change: %v on %s",
 		c.NewValue.GetValue(), opcnode.ToDebugString(&c.Node)))
 	m := s.OpcServer.NamespaceManager()
 	if node, ok := m.FindVariable(ua.NewNodeIDGUID(2, c.Node.Id)); !ok {
 		s.Logger.Warn(fmt.Sprintf("node %s not found", opcnode.ToDebugString(&c.Node)))
 	} else {
 		var v ua.Variant = c.NewValue.GetValue()
-		node.SetValue(ua.NewDataValue(v, ua.Good, time.Now(), 0, time.Now(), 0))
+		node.SetValue(ua.NewDataValue(v, ua.StatusCode(c.Status), c.Timestamp, 0, time.Now(), 0))
 	}
 }
 
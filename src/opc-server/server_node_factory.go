@@ -14,6 +14,9 @@ func makeNode(r opcnode.OpcStructureNode, p ua.NodeID, s *server.Server) (server
 	switch t := r.(type) {
 	case *opcnode.OpcContainerNode:
 		return makeContainerNode(*t, p, s)
+	case *opcnode.OpcTemplateNode:
+		// templates appear as a folder of their signals
+		return makeContainerNode(opcnode.OpcContainerNode{Id: t.Id, Label: t.Label}, p, s)
 	case *opcnode.OpcValueNode:
 		return makeValueNode(*t, p, s)
 	default:
@@ -48,21 +51,24 @@ func makeContainerNode(n opcnode.OpcContainerNode, p ua.NodeID, s *server.Server
 }
 
 func makeValueNode(n opcnode.OpcValueNode, p ua.NodeID, s *server.Server) (server.Node, error) {
-	nodeIdMap := map[waveform.WaveformType]ua.NodeID{
-		waveform.Transitions:   ua.NewNodeIDNumeric(0, 1),
-		waveform.NumericValues: ua.NewNodeIDNumeric(0, 11),
+	nodeIdMap := map[waveform.ValueType]ua.NodeID{
+		waveform.BooleanValue: ua.NewNodeIDNumeric(0, 1),
+		waveform.DoubleValue:  ua.NewNodeIDNumeric(0, 11),
+		waveform.IntegerValue: ua.NewNodeIDNumeric(0, 6),
 	}
-	defaultValueMap := map[waveform.WaveformType]ua.Variant{
-		waveform.Transitions:   false,
-		waveform.NumericValues: float64(0),
+	defaultValueMap := map[waveform.ValueType]ua.Variant{
+		waveform.BooleanValue: false,
+		waveform.DoubleValue:  float64(0),
+		waveform.IntegerValue: int32(0),
 	}
-	typeNodeId, found := nodeIdMap[n.Waveform.WaveformType]
+	valueType := n.Waveform.GetValueType()
+	typeNodeId, found := nodeIdMap[valueType]
 	if !found {
-		return nil, fmt.Errorf("invalid waveform type %v", n.Waveform.WaveformType)
+		return nil, fmt.Errorf("invalid value type %v", valueType)
 	}
-	defaultValue, found := defaultValueMap[n.Waveform.WaveformType]
+	defaultValue, found := defaultValueMap[valueType]
 	if !found {
-		return nil, fmt.Errorf("invalid waveform type %v", n.Waveform.WaveformType)
+		return nil, fmt.Errorf("invalid value type %v", valueType)
 	}
 
 	return server.NewVariableNode(
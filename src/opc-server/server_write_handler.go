@@ -0,0 +1,74 @@
+package opcserver
+
+import (
+	"fmt"
+	"time"
+
+	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
+	opcnode "github.com/AndreiLacatos/opc-engine/node-engine/models/opc/opc_node"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/waveform"
+	waveformvalue "github.com/AndreiLacatos/opc-engine/node-engine/models/waveform/waveform_value"
+	"github.com/awcullen/opcua/server"
+	"github.com/awcullen/opcua/ua"
+)
+
+func (s *opcServerImpl) makeWriteHandler(n opcnode.OpcValueNode) func(*server.Session, ua.WriteValue) (ua.DataValue, ua.StatusCode) {
+	valueType := n.Waveform.GetValueType()
+
+	// writes to writable nodes suspend the simulation, clients
+	// are made aware of it through the status of the value
+	status := ua.Good
+	if n.Override != nil {
+		status = ua.GoodLocalOverride
+	}
+
+	return func(_ *server.Session, w ua.WriteValue) (ua.DataValue, ua.StatusCode) {
+		v, ok := toWaveformValue(w.Value.Value, valueType)
+		if !ok {
+			s.Logger.Warn(fmt.Sprintf("rejected write of %v to %s, type mismatch", w.Value.Value, opcnode.ToDebugString(&n)))
+			return ua.DataValue{}, ua.BadTypeMismatch
+		}
+
+		select {
+		case s.Writes <- nodeengine.NodeValueWrite{NodeId: n.Id, Value: v}:
+		default:
+			s.Logger.Warn(fmt.Sprintf("too many pending writes, write to %s not forwarded to the engine", opcnode.ToDebugString(&n)))
+		}
+
+		sourceTimestamp := w.Value.SourceTimestamp
+		if sourceTimestamp.IsZero() {
+			sourceTimestamp = time.Now()
+		}
+		return ua.NewDataValue(w.Value.Value, status, sourceTimestamp, 0, time.Now(), 0), ua.Good
+	}
+}
+
+func toWaveformValue(v any, t waveform.ValueType) (waveformvalue.WaveformPointValue, bool) {
+	switch t {
+	case waveform.BooleanValue:
+		if b, ok := v.(bool); ok {
+			return &waveformvalue.Transition{Value: b}, true
+		}
+	case waveform.DoubleValue:
+		switch n := v.(type) {
+		case float64:
+			return &waveformvalue.DoubleValue{Value: n}, true
+		case float32:
+			return &waveformvalue.DoubleValue{Value: float64(n)}, true
+		}
+	case waveform.IntegerValue:
+		switch n := v.(type) {
+		case int32:
+			return &waveformvalue.IntegerValue{Value: n}, true
+		case int16:
+			return &waveformvalue.IntegerValue{Value: int32(n)}, true
+		case uint16:
+			return &waveformvalue.IntegerValue{Value: int32(n)}, true
+		case int8:
+			return &waveformvalue.IntegerValue{Value: int32(n)}, true
+		case uint8:
+			return &waveformvalue.IntegerValue{Value: int32(n)}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,29 @@
+package statestore
+
+import (
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+	"go.uber.org/zap"
+)
+
+// StoredStructure is a structure applied at some point along with the
+// origin of it, either a project file or a configuration server client
+type StoredStructure struct {
+	AppliedAt time.Time                       `json:"appliedAt"`
+	Origin    string                          `json:"origin"`
+	Structure serialization.OpcStructureModel `json:"structure"`
+}
+
+type StateStore interface {
+	init(string, int, *zap.Logger)
+	Save(StoredStructure) error
+	LoadAll() ([]StoredStructure, error)
+}
+
+// CreateNew makes a store keeping the last retention structures in dir
+func CreateNew(dir string, retention int, l *zap.Logger) StateStore {
+	s := stateStoreImpl{}
+	s.init(dir, retention, l.Named("STATE"))
+	return &s
+}
@@ -0,0 +1,129 @@
+package statestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	structureFilePrefix = "structure-"
+	structureFileSuffix = ".json"
+)
+
+type stateStoreImpl struct {
+	dir       string
+	retention int
+	logger    *zap.Logger
+}
+
+func (s *stateStoreImpl) init(dir string, retention int, l *zap.Logger) {
+	s.dir = dir
+	s.retention = max(retention, 1)
+	s.logger = l
+}
+
+// Save stores the structure in a new file numbered after the last stored one,
+// the file is written at once so that a crash never leaves it half written;
+// the oldest files beyond the retention are removed afterwards
+func (s *stateStoreImpl) Save(st StoredStructure) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	stored, err := s.listStored()
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(stored) != 0 {
+		next = stored[len(stored)-1] + 1
+	}
+
+	tmp, err := os.CreateTemp(s.dir, structureFilePrefix+"*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.getPath(next)); err != nil {
+		return err
+	}
+	s.logger.Debug(fmt.Sprintf("stored structure %d applied on %v by %s", next, st.AppliedAt, st.Origin))
+
+	stored = append(stored, next)
+	for _, n := range stored[:max(len(stored)-s.retention, 0)] {
+		if err := os.Remove(s.getPath(n)); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn(fmt.Sprintf("could not remove stored structure %d, reason: %v", n, err))
+		}
+	}
+	return nil
+}
+
+// LoadAll returns the stored structures, the latest first; files that
+// can not be read are skipped so that the ones before them are kept
+func (s *stateStoreImpl) LoadAll() ([]StoredStructure, error) {
+	stored, err := s.listStored()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]StoredStructure, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		content, err := os.ReadFile(s.getPath(stored[i]))
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("could not read stored structure %d, reason: %v", stored[i], err))
+			continue
+		}
+		var st StoredStructure
+		if err := json.Unmarshal(content, &st); err != nil {
+			s.logger.Warn(fmt.Sprintf("could not parse stored structure %d, reason: %v", stored[i], err))
+			continue
+		}
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// listStored returns the numbers of the stored structure files in ascending order
+func (s *stateStoreImpl) listStored() ([]int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, structureFilePrefix) || !strings.HasSuffix(name, structureFileSuffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, structureFilePrefix), structureFileSuffix))
+		if err != nil || n <= 0 {
+			continue
+		}
+		res = append(res, n)
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+func (s *stateStoreImpl) getPath(n int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%06d%s", structureFilePrefix, n, structureFileSuffix))
+}
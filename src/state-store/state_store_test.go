@@ -0,0 +1,113 @@
+package statestore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+	"go.uber.org/zap"
+)
+
+func TestLoadAll(t *testing.T) {
+	appliedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	save := func(t *testing.T, s StateStore, origins ...string) {
+		for i, o := range origins {
+			st := StoredStructure{
+				AppliedAt: appliedAt.Add(time.Duration(i) * time.Minute),
+				Origin:    o,
+				Structure: serialization.OpcStructureModel{
+					Root: serialization.OpcStructureNodeModel{Id: "2c3d4e5f-6a7b-4c8d-9e0f-1a2b3c4d5e6f", Label: o, NodeType: "container"},
+				},
+			}
+			if err := s.Save(st); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, tc := range []struct {
+		name      string
+		retention int
+		prepare   func(*testing.T, StateStore, string)
+		want      []string
+	}{
+		{
+			name:      "saved structures, latest first",
+			retention: 10,
+			prepare: func(t *testing.T, s StateStore, _ string) {
+				save(t, s, "project.opcproj", "192.0.2.10:51234", "192.0.2.11:40000")
+			},
+			want: []string{"192.0.2.11:40000", "192.0.2.10:51234", "project.opcproj"},
+		},
+		{
+			name:      "oldest structures beyond the retention removed",
+			retention: 2,
+			prepare: func(t *testing.T, s StateStore, _ string) {
+				save(t, s, "project.opcproj", "192.0.2.10:51234", "192.0.2.11:40000")
+			},
+			want: []string{"192.0.2.11:40000", "192.0.2.10:51234"},
+		},
+		{
+			name:      "nothing stored",
+			retention: 10,
+			prepare:   func(*testing.T, StateStore, string) {},
+			want:      []string{},
+		},
+		{
+			name:      "corrupt latest file skipped",
+			retention: 10,
+			prepare: func(t *testing.T, s StateStore, dir string) {
+				save(t, s, "project.opcproj")
+				if err := os.WriteFile(filepath.Join(dir, "structure-000002.json"), []byte(`{"origin": `), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"project.opcproj"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := CreateNew(dir, tc.retention, zap.NewNop())
+			tc.prepare(t, s, dir)
+
+			got, err := s.LoadAll()
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d structures, got %+v", len(tc.want), got)
+			}
+			for i, o := range tc.want {
+				if got[i].Origin != o || got[i].Structure.Root.Label != o {
+					t.Errorf("expected structure %d from %s, got %+v", i, o, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSave_KeepsTheStructuresBeforeIt(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	s := CreateNew(dir, 10, zap.NewNop())
+	for _, o := range []string{"good", "bad"} {
+		if err := s.Save(StoredStructure{Origin: o}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "structure-000001.json" || entries[1].Name() != "structure-000002.json" {
+		t.Errorf("expected a file per structure & no temporary files, got %v", entries)
+	}
+	got, err := s.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].Origin != "good" {
+		t.Errorf("expected the structure applied before the latest to be kept, got %+v", got)
+	}
+}
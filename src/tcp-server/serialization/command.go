@@ -1,13 +1,132 @@
 package serialization
 
-import opcserialization "github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+import (
+	"encoding/json"
+	"time"
+
+	opcserialization "github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+)
 
 type Command struct {
-	Command string                             `json:"command"`
-	Payload opcserialization.OpcStructureModel `json:"payload"`
+	Command          string          `json:"command"`
+	ExpectedRevision *int            `json:"expectedRevision,omitempty"`
+	Payload          json.RawMessage `json:"payload"`
+}
+
+type NodeCommandModel struct {
+	NodeId string `json:"nodeId"`
+}
+
+type FaultCommandModel struct {
+	NodeId string                       `json:"nodeId"`
+	Fault  *opcserialization.FaultModel `json:"fault,omitempty"`
+}
+
+type AddNodeCommandModel struct {
+	ParentId string                                  `json:"parentId"`
+	Node     *opcserialization.OpcStructureNodeModel `json:"node"`
+}
+
+type UpdateNodeCommandModel struct {
+	NodeId   string                          `json:"nodeId"`
+	Label    *string                         `json:"label,omitempty"`
+	Waveform *opcserialization.WaveformModel `json:"waveform,omitempty"`
+}
+
+type MoveNodeCommandModel struct {
+	NodeId   string `json:"nodeId"`
+	ParentId string `json:"parentId"`
+}
+
+type NodeQueryModel struct {
+	NodeId string `json:"nodeId,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+type ValuesQueryModel struct {
+	NodeIds []string `json:"nodeIds,omitempty"`
+}
+
+type NodeValueModel struct {
+	NodeId    string     `json:"nodeId"`
+	Label     string     `json:"label"`
+	Value     any        `json:"value"`
+	Status    *string    `json:"status"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type NodeStatusModel struct {
+	NodeValueModel
+	Active     bool     `json:"active"`
+	Disabled   bool     `json:"disabled"`
+	Overridden bool     `json:"overridden"`
+	Faults     []string `json:"faults"`
+}
+
+type EngineStatusModel struct {
+	Running bool              `json:"running"`
+	Idle    bool              `json:"idle"`
+	Speed   float64           `json:"speed"`
+	Uptime  int64             `json:"uptime"`
+	Nodes   []NodeStatusModel `json:"nodes"`
+}
+
+type VersionModel struct {
+	Version   string    `json:"version"`
+	BuildTime time.Time `json:"buildTime"`
+	GoVersion string    `json:"goVersion"`
+}
+
+type OverrideStatusModel struct {
+	NodeId string     `json:"nodeId"`
+	Label  string     `json:"label"`
+	Mode   string     `json:"mode"`
+	Value  any        `json:"value"`
+	Since  time.Time  `json:"since"`
+	Until  *time.Time `json:"until"`
+}
+
+type ScenarioCommandModel struct {
+	Name string `json:"name"`
+}
+
+type ScenarioStatusModel struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	Elapsed int64  `json:"elapsed"`
+}
+
+type RevisionCommandModel struct {
+	Revision int `json:"revision"`
+}
+
+type RevisionDiffCommandModel struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+type RevisionModel struct {
+	Revision  int       `json:"revision"`
+	AppliedAt time.Time `json:"appliedAt"`
+	Origin    string    `json:"origin"`
+	Summary   string    `json:"summary"`
+}
+
+type NodeChangeModel struct {
+	NodeId   string  `json:"nodeId"`
+	Label    string  `json:"label"`
+	ParentId *string `json:"parentId"`
+}
+
+type RevisionDiffModel struct {
+	Removed []NodeChangeModel `json:"removed"`
+	Added   []NodeChangeModel `json:"added"`
+	Updated []NodeChangeModel `json:"updated"`
 }
 
 type Respose struct {
-	Status string  `json:"status"`
-	Reason *string `json:"reason"`
+	Status   string  `json:"status"`
+	Reason   *string `json:"reason"`
+	Revision int     `json:"revision"`
+	Data     any     `json:"data,omitempty"`
 }
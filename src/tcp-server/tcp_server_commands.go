@@ -0,0 +1,135 @@
+package tcpserver
+
+import (
+	"fmt"
+
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/fault"
+	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	"github.com/AndreiLacatos/opc-engine/node-engine/serialization"
+	"github.com/google/uuid"
+)
+
+// ConfigureNodesCommand replaces the node structure, the model is the
+// structure as sent by the client at Origin; if ExpectedRevision is set
+// the structure is only applied if it is still the current revision
+type ConfigureNodesCommand struct {
+	Structure        opc.OpcStructure
+	Model            serialization.OpcStructureModel
+	Origin           string
+	ExpectedRevision *int
+}
+
+// InjectFaultCommand triggers a fault on a value node on demand
+type InjectFaultCommand struct {
+	NodeId uuid.UUID
+	Fault  fault.Fault
+}
+
+// ClearFaultsCommand removes the on demand faults of a value node
+type ClearFaultsCommand struct {
+	NodeId uuid.UUID
+}
+
+// GetOverridesCommand lists the writable nodes currently overridden by clients
+type GetOverridesCommand struct{}
+
+// ClearOverrideCommand hands a writable node back to the simulation
+type ClearOverrideCommand struct {
+	NodeId uuid.UUID
+}
+
+// GetScenariosCommand lists the scenarios of the running structure
+type GetScenariosCommand struct{}
+
+// StartScenarioCommand plays a scenario by name, stopping the one already running
+type StartScenarioCommand struct {
+	Name string
+}
+
+// StopScenarioCommand stops the running scenario, its applied actions are kept
+type StopScenarioCommand struct{}
+
+// ListRevisionsCommand lists the structures applied lately
+type ListRevisionsCommand struct{}
+
+// DiffRevisionsCommand compares the structures of two revisions
+type DiffRevisionsCommand struct {
+	From int
+	To   int
+}
+
+// RollbackCommand applies the structure of a previous revision again
+type RollbackCommand struct {
+	Revision         int
+	Origin           string
+	ExpectedRevision *int
+}
+
+// AddNodeCommand adds a node along with its subtree under a container
+type AddNodeCommand struct {
+	ParentId         uuid.UUID
+	Node             serialization.OpcStructureNodeModel
+	Origin           string
+	ExpectedRevision *int
+}
+
+// RemoveNodeCommand removes a node along with its subtree
+type RemoveNodeCommand struct {
+	NodeId           uuid.UUID
+	Origin           string
+	ExpectedRevision *int
+}
+
+// UpdateNodeCommand changes the label and/or the waveform of a node,
+// fields left nil are kept
+type UpdateNodeCommand struct {
+	NodeId           uuid.UUID
+	Label            *string
+	Waveform         *serialization.WaveformModel
+	Origin           string
+	ExpectedRevision *int
+}
+
+// MoveNodeCommand moves a node along with its subtree under another container
+type MoveNodeCommand struct {
+	NodeId           uuid.UUID
+	ParentId         uuid.UUID
+	Origin           string
+	ExpectedRevision *int
+}
+
+// GetStructureCommand returns the running structure, as applied
+type GetStructureCommand struct{}
+
+// GetNodeCommand looks up a node of the running structure by id,
+// or by browse path if no id is given
+type GetNodeCommand struct {
+	NodeId *uuid.UUID
+	Path   string
+}
+
+// GetValuesCommand returns the last published values of the
+// given value nodes, or of every value node if none is given
+type GetValuesCommand struct {
+	NodeIds []uuid.UUID
+}
+
+// GetStatusCommand returns a snapshot of the simulation
+type GetStatusCommand struct{}
+
+// GetVersionCommand returns the version & build info of the simulator
+type GetVersionCommand struct{}
+
+// GetRevisionCommand returns the number of the current structure revision
+type GetRevisionCommand struct{}
+
+// ConflictError reports a change based on a structure revision
+// that is no longer the current one
+type ConflictError struct {
+	Expected int
+	Current  int
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("structure changed meanwhile, expected revision %d, current revision is %d", e.Expected, e.Current)
+}
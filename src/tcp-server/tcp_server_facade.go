@@ -1,18 +1,22 @@
 package tcpserver
 
-import (
-	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
-	"go.uber.org/zap"
-)
+import "go.uber.org/zap"
 
 type TcpServer interface {
 	Setup()
 	Start() error
-	GetCommandChannel() chan opc.OpcStructure
-	GetResponseChannel() chan error
+	GetCommandChannel() chan any
+	GetResponseChannel() chan CommandResult
 	Stop() error
 }
 
+// CommandResult is the outcome of a command, as reported
+// by the consumer of the command channel
+type CommandResult struct {
+	Data any
+	Err  error
+}
+
 type TcpServerConfig struct {
 	Host string
 	Port uint16
@@ -4,13 +4,21 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
+	"runtime"
 	"strings"
 
+	"github.com/AndreiLacatos/opc-engine/config"
+	confighistory "github.com/AndreiLacatos/opc-engine/config-history"
+	nodeengine "github.com/AndreiLacatos/opc-engine/node-engine"
 	"github.com/AndreiLacatos/opc-engine/node-engine/models/opc"
+	opcserialization "github.com/AndreiLacatos/opc-engine/node-engine/serialization"
 	"github.com/AndreiLacatos/opc-engine/tcp-server/serialization"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -20,17 +28,42 @@ type TcpServerImpl struct {
 	Logger     *zap.Logger
 	Listener   *net.Listener
 	Done       chan bool
-	Command    chan opc.OpcStructure
-	Response   chan error
-	CommandMap map[string]func(any) error
+	Command    chan any
+	Response   chan CommandResult
+	CommandMap map[string]func(json.RawMessage, request) (any, error)
+}
+
+// request holds the details of a command besides its payload
+type request struct {
+	origin           string
+	expectedRevision *int
 }
 
 func (s *TcpServerImpl) Setup() {
 	s.Done = make(chan bool, 1)
-	s.Command = make(chan opc.OpcStructure, 1)
-	s.Response = make(chan error, 1)
-	s.CommandMap = map[string]func(any) error{
+	s.Command = make(chan any, 1)
+	s.Response = make(chan CommandResult, 1)
+	s.CommandMap = map[string]func(json.RawMessage, request) (any, error){
 		"configure nodes": s.handleConfigureNodes,
+		"inject fault":    s.handleInjectFault,
+		"clear faults":    s.handleClearFaults,
+		"get overrides":   s.handleGetOverrides,
+		"clear override":  s.handleClearOverride,
+		"list scenarios":  s.handleListScenarios,
+		"start scenario":  s.handleStartScenario,
+		"stop scenario":   s.handleStopScenario,
+		"list revisions":  s.handleListRevisions,
+		"diff revisions":  s.handleDiffRevisions,
+		"rollback":        s.handleRollback,
+		"add node":        s.handleAddNode,
+		"remove node":     s.handleRemoveNode,
+		"update node":     s.handleUpdateNode,
+		"move node":       s.handleMoveNode,
+		"get structure":   s.handleGetStructure,
+		"get node":        s.handleGetNode,
+		"get values":      s.handleGetValues,
+		"get status":      s.handleGetStatus,
+		"get version":     s.handleGetVersion,
 	}
 }
 
@@ -61,10 +94,10 @@ func (s *TcpServerImpl) Start() error {
 	}
 }
 
-func (s *TcpServerImpl) GetCommandChannel() chan opc.OpcStructure {
+func (s *TcpServerImpl) GetCommandChannel() chan any {
 	return s.Command
 }
-func (s *TcpServerImpl) GetResponseChannel() chan error {
+func (s *TcpServerImpl) GetResponseChannel() chan CommandResult {
 	return s.Response
 }
 
@@ -97,39 +130,56 @@ func (s *TcpServerImpl) handleConnection(c net.Conn) error {
 	if err != nil {
 		s.Logger.Error("error parsing client message")
 		msg := "invalid message"
-		res, _ := json.Marshal(serialization.Respose{
+		s.respond(c, serialization.Respose{
 			Status: "failure",
 			Reason: &msg,
 		})
-		c.Write(res)
 		return err
 	}
 
 	if handler, found := s.CommandMap[strings.ToLower(command.Command)]; !found {
 		s.Logger.Warn(fmt.Sprintf("unrecognized client command %s", command.Command))
 		msg := "unrecognized client"
-		res, _ := json.Marshal(serialization.Respose{
+		s.respond(c, serialization.Respose{
 			Status: "failure",
 			Reason: &msg,
 		})
-		c.Write(res)
 		return nil
 	} else {
-		err = handler(command.Payload.ToDomain(s.Logger))
+		data, err := handler(command.Payload, request{
+			origin:           c.RemoteAddr().String(),
+			expectedRevision: command.ExpectedRevision,
+		})
 		var res serialization.Respose
-		if err != nil {
+		var conflict ConflictError
+		if errors.As(err, &conflict) {
+			msg := err.Error()
+			res.Status = "conflict"
+			res.Reason = &msg
+		} else if err != nil {
 			msg := err.Error()
 			res.Status = "failure"
 			res.Reason = &msg
 		} else {
 			res.Status = "success"
+			res.Data = data
 		}
-		resJson, _ := json.Marshal(res)
-		c.Write(resJson)
+		s.respond(c, res)
 		return err
 	}
 }
 
+// respond writes the response along with the current structure revision
+func (s *TcpServerImpl) respond(c net.Conn, res serialization.Respose) {
+	if revision, err := s.dispatch(GetRevisionCommand{}); err == nil {
+		res.Revision = revision.(int)
+	} else {
+		s.Logger.Warn(fmt.Sprintf("could not get current revision, reason: %v", err))
+	}
+	resJson, _ := json.Marshal(res)
+	c.Write(resJson)
+}
+
 func (s *TcpServerImpl) readMessage(c net.Conn) (string, error) {
 	reader := bufio.NewReader(c)
 	var buffer bytes.Buffer
@@ -153,20 +203,452 @@ func (s *TcpServerImpl) parseCommand(m string) (*serialization.Command, error) {
 	return &command, nil
 }
 
-func (s *TcpServerImpl) handleConfigureNodes(p any) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			s.Logger.Error("input is not OPC structure")
-			err = fmt.Errorf("invalid input")
-		}
-	}()
-	structure := p.(opc.OpcStructure)
+func (s *TcpServerImpl) handleConfigureNodes(p json.RawMessage, r request) (any, error) {
+	var m opcserialization.OpcStructureModel
+	if err := json.Unmarshal(p, &m); err != nil {
+		s.Logger.Error("input is not OPC structure")
+		return nil, fmt.Errorf("invalid input")
+	}
 
-	s.Command <- structure
-	if err = <-s.Response; err != nil {
+	if _, err := s.dispatch(ConfigureNodesCommand{
+		Structure:        m.ToDomain(s.Logger),
+		Model:            m,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	}); err != nil {
 		s.Logger.Error(fmt.Sprintf("failed to apply new OPC node structure, reason: %v", err))
-		return err
-	} else {
-		return nil
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleInjectFault(p json.RawMessage, _ request) (any, error) {
+	var m serialization.FaultCommandModel
+	if err := json.Unmarshal(p, &m); err != nil || m.Fault == nil {
+		s.Logger.Error("input is not a fault command")
+		return nil, fmt.Errorf("invalid input")
+	}
+	id, err := uuid.Parse(m.NodeId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", m.NodeId)
+	}
+	f, err := m.Fault.ToDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.dispatch(InjectFaultCommand{NodeId: id, Fault: *f}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to inject fault, reason: %v", err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleClearFaults(p json.RawMessage, _ request) (any, error) {
+	id, err := s.parseNodeId(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.dispatch(ClearFaultsCommand{NodeId: id}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to clear faults, reason: %v", err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleGetOverrides(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(GetOverridesCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get overrides, reason: %v", err))
+		return nil, err
+	}
+
+	overrides := res.([]nodeengine.NodeOverride)
+	m := make([]serialization.OverrideStatusModel, len(overrides))
+	for i, o := range overrides {
+		m[i] = serialization.OverrideStatusModel{
+			NodeId: o.Node.Id.String(),
+			Label:  o.Node.Label,
+			Mode:   opcserialization.MapOverrideMode(o.State.Mode),
+			Value:  o.State.Value.GetValue(),
+			Since:  o.State.Since,
+			Until:  o.State.Until,
+		}
+	}
+	return m, nil
+}
+
+func (s *TcpServerImpl) handleClearOverride(p json.RawMessage, _ request) (any, error) {
+	id, err := s.parseNodeId(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.dispatch(ClearOverrideCommand{NodeId: id}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to clear override, reason: %v", err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleListScenarios(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(GetScenariosCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to list scenarios, reason: %v", err))
+		return nil, err
+	}
+
+	scenarios := res.([]nodeengine.ScenarioStatus)
+	m := make([]serialization.ScenarioStatusModel, len(scenarios))
+	for i, sc := range scenarios {
+		m[i] = serialization.ScenarioStatusModel{
+			Name:    sc.Name,
+			Running: sc.Running,
+			Elapsed: sc.Elapsed.Milliseconds(),
+		}
+	}
+	return m, nil
+}
+
+func (s *TcpServerImpl) handleStartScenario(p json.RawMessage, _ request) (any, error) {
+	var m serialization.ScenarioCommandModel
+	if err := json.Unmarshal(p, &m); err != nil || m.Name == "" {
+		s.Logger.Error("input is not a scenario command")
+		return nil, fmt.Errorf("invalid input")
+	}
+
+	if _, err := s.dispatch(StartScenarioCommand{Name: m.Name}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to start scenario, reason: %v", err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleStopScenario(p json.RawMessage, _ request) (any, error) {
+	if _, err := s.dispatch(StopScenarioCommand{}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to stop scenario, reason: %v", err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleListRevisions(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(ListRevisionsCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to list revisions, reason: %v", err))
+		return nil, err
+	}
+
+	revisions := res.([]confighistory.Revision)
+	m := make([]serialization.RevisionModel, len(revisions))
+	for i, r := range revisions {
+		m[i] = serialization.RevisionModel{
+			Revision:  r.Number,
+			AppliedAt: r.AppliedAt,
+			Origin:    r.Origin,
+			Summary:   r.Summary,
+		}
+	}
+	return m, nil
+}
+
+func (s *TcpServerImpl) handleDiffRevisions(p json.RawMessage, _ request) (any, error) {
+	var m serialization.RevisionDiffCommandModel
+	if err := json.Unmarshal(p, &m); err != nil {
+		s.Logger.Error("input is not a revision diff command")
+		return nil, fmt.Errorf("invalid input")
+	}
+
+	res, err := s.dispatch(DiffRevisionsCommand{From: m.From, To: m.To})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to diff revisions, reason: %v", err))
+		return nil, err
+	}
+
+	d := res.(opc.StructureDiff)
+	return serialization.RevisionDiffModel{
+		Removed: mapNodeChanges(d.Removed),
+		Added:   mapNodeChanges(d.Added),
+		Updated: mapNodeChanges(d.Updated),
+	}, nil
+}
+
+func (s *TcpServerImpl) handleRollback(p json.RawMessage, r request) (any, error) {
+	var m serialization.RevisionCommandModel
+	if err := json.Unmarshal(p, &m); err != nil {
+		s.Logger.Error("input is not a rollback command")
+		return nil, fmt.Errorf("invalid input")
+	}
+
+	if _, err := s.dispatch(RollbackCommand{
+		Revision:         m.Revision,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	}); err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to roll back to revision %d, reason: %v", m.Revision, err))
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (s *TcpServerImpl) handleAddNode(p json.RawMessage, r request) (any, error) {
+	var m serialization.AddNodeCommandModel
+	if err := json.Unmarshal(p, &m); err != nil || m.Node == nil {
+		s.Logger.Error("input is not an add node command")
+		return nil, fmt.Errorf("invalid input")
+	}
+	parentId, err := uuid.Parse(m.ParentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", m.ParentId)
 	}
+	if err := m.Node.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid node: %w", err)
+	}
+
+	res, err := s.dispatch(AddNodeCommand{
+		ParentId:         parentId,
+		Node:             *m.Node,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to add node, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleRemoveNode(p json.RawMessage, r request) (any, error) {
+	id, err := s.parseNodeId(p)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.dispatch(RemoveNodeCommand{
+		NodeId:           id,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to remove node, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleUpdateNode(p json.RawMessage, r request) (any, error) {
+	var m serialization.UpdateNodeCommandModel
+	if err := json.Unmarshal(p, &m); err != nil || (m.Label == nil && m.Waveform == nil) {
+		s.Logger.Error("input is not an update node command")
+		return nil, fmt.Errorf("invalid input")
+	}
+	id, err := uuid.Parse(m.NodeId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", m.NodeId)
+	}
+
+	res, err := s.dispatch(UpdateNodeCommand{
+		NodeId:           id,
+		Label:            m.Label,
+		Waveform:         m.Waveform,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to update node, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleMoveNode(p json.RawMessage, r request) (any, error) {
+	var m serialization.MoveNodeCommandModel
+	if err := json.Unmarshal(p, &m); err != nil {
+		s.Logger.Error("input is not a move node command")
+		return nil, fmt.Errorf("invalid input")
+	}
+	id, err := uuid.Parse(m.NodeId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", m.NodeId)
+	}
+	parentId, err := uuid.Parse(m.ParentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid node id", m.ParentId)
+	}
+
+	res, err := s.dispatch(MoveNodeCommand{
+		NodeId:           id,
+		ParentId:         parentId,
+		Origin:           r.origin,
+		ExpectedRevision: r.expectedRevision,
+	})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to move node, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleGetStructure(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(GetStructureCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get structure, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleGetNode(p json.RawMessage, _ request) (any, error) {
+	var m serialization.NodeQueryModel
+	if err := json.Unmarshal(p, &m); err != nil || (m.NodeId == "" && m.Path == "") {
+		s.Logger.Error("input is not a node query")
+		return nil, fmt.Errorf("invalid input")
+	}
+	c := GetNodeCommand{Path: m.Path}
+	if m.NodeId != "" {
+		id, err := uuid.Parse(m.NodeId)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid node id", m.NodeId)
+		}
+		c.NodeId = &id
+	}
+
+	res, err := s.dispatch(c)
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get node, reason: %v", err))
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *TcpServerImpl) handleGetValues(p json.RawMessage, _ request) (any, error) {
+	var m serialization.ValuesQueryModel
+	if len(p) > 0 {
+		if err := json.Unmarshal(p, &m); err != nil {
+			s.Logger.Error("input is not a values query")
+			return nil, fmt.Errorf("invalid input")
+		}
+	}
+	ids := make([]uuid.UUID, len(m.NodeIds))
+	for i, n := range m.NodeIds {
+		id, err := uuid.Parse(n)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid node id", n)
+		}
+		ids[i] = id
+	}
+
+	res, err := s.dispatch(GetValuesCommand{NodeIds: ids})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get values, reason: %v", err))
+		return nil, err
+	}
+
+	values := res.([]nodeengine.NodeValue)
+	v := make([]serialization.NodeValueModel, len(values))
+	for i, n := range values {
+		v[i] = mapNodeValue(n)
+	}
+	return v, nil
+}
+
+func (s *TcpServerImpl) handleGetStatus(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(GetStatusCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get status, reason: %v", err))
+		return nil, err
+	}
+
+	status := res.(nodeengine.EngineStatus)
+	m := serialization.EngineStatusModel{
+		Running: status.Running,
+		Idle:    status.Idle,
+		Speed:   status.Speed,
+		Uptime:  status.Uptime.Milliseconds(),
+		Nodes:   make([]serialization.NodeStatusModel, len(status.Nodes)),
+	}
+	for i, n := range status.Nodes {
+		faults := make([]string, len(n.Faults))
+		for j, f := range n.Faults {
+			faults[j] = opcserialization.MapFaultType(f)
+		}
+		m.Nodes[i] = serialization.NodeStatusModel{
+			NodeValueModel: mapNodeValue(n.NodeValue),
+			Active:         n.Active,
+			Disabled:       n.Disabled,
+			Overridden:     n.Overridden,
+			Faults:         faults,
+		}
+	}
+	return m, nil
+}
+
+func (s *TcpServerImpl) handleGetVersion(p json.RawMessage, _ request) (any, error) {
+	res, err := s.dispatch(GetVersionCommand{})
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("failed to get version, reason: %v", err))
+		return nil, err
+	}
+
+	c := res.(config.Config)
+	return serialization.VersionModel{
+		Version:   c.Version,
+		BuildTime: c.BuildTime,
+		GoVersion: runtime.Version(),
+	}, nil
+}
+
+func mapNodeValue(n nodeengine.NodeValue) serialization.NodeValueModel {
+	m := serialization.NodeValueModel{
+		NodeId:    n.Node.Id.String(),
+		Label:     n.Node.Label,
+		Timestamp: n.Timestamp,
+	}
+	if n.Value != nil {
+		m.Value = n.Value.GetValue()
+		// NaN & infinity have no JSON representation
+		if f, ok := m.Value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			m.Value = fmt.Sprint(f)
+		}
+		status := opcserialization.MapStatusCode(n.Status)
+		m.Status = &status
+	}
+	return m
+}
+
+func mapNodeChanges(c []opc.NodeChange) []serialization.NodeChangeModel {
+	res := make([]serialization.NodeChangeModel, len(c))
+	for i, n := range c {
+		res[i] = serialization.NodeChangeModel{
+			NodeId: n.Node.GetId().String(),
+			Label:  n.Node.GetLabel(),
+		}
+		if n.Parent != uuid.Nil {
+			parent := n.Parent.String()
+			res[i].ParentId = &parent
+		}
+	}
+	return res
+}
+
+func (s *TcpServerImpl) parseNodeId(p json.RawMessage) (uuid.UUID, error) {
+	var m serialization.NodeCommandModel
+	if err := json.Unmarshal(p, &m); err != nil {
+		s.Logger.Error("input is not a node command")
+		return uuid.Nil, fmt.Errorf("invalid input")
+	}
+	id, err := uuid.Parse(m.NodeId)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s is not a valid node id", m.NodeId)
+	}
+	return id, nil
+}
+
+// dispatch passes the command to the consumer of the command
+// channel & waits for the outcome
+func (s *TcpServerImpl) dispatch(c any) (any, error) {
+	s.Command <- c
+	r := <-s.Response
+	return r.Data, r.Err
 }